@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/relvacode/iso8601"
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// Defaults for AirQualityOptions
+const (
+	// DefaultAirQualityBaseURL points at the free Open-Meteo Air Quality API.
+	DefaultAirQualityBaseURL = "https://air-quality-api.open-meteo.com/v1/air-quality"
+	// DefaultAirQualityDays is the longest forecast offered by the Air Quality API.
+	DefaultAirQualityDays = 7
+)
+
+// airQualityHourly lists the hourly variables required to build model.HourlyAirQuality.
+var airQualityHourly = []string{"pm2_5", "pm10", "ozone", "european_aqi", "us_aqi"}
+
+// AirQualityProvider is implemented by upstream air quality sources.
+type AirQualityProvider interface {
+	// Name identifies the provider in logs.
+	Name() string
+	// FetchHourlyAirQuality returns the hourly air quality forecast for the
+	// location, ordered by time and expressed in UTC.
+	FetchHourlyAirQuality(ctx context.Context, lat, long float64) ([]model.HourlyAirQuality, error)
+}
+
+// AirQualityOptions configures an AirQualityClient. Zero values fall back to the defaults.
+type AirQualityOptions struct {
+	OpenMeteoEndpoint
+}
+
+// AirQualityClient fetches air pollutant and AQI forecasts from the Open-Meteo Air Quality API.
+type AirQualityClient struct {
+	api *openMeteoAPI
+}
+
+func NewAirQualityClient(opts AirQualityOptions) *AirQualityClient {
+	return &AirQualityClient{
+		api: newOpenMeteoAPI("open-meteo-air-quality", opts.OpenMeteoEndpoint, DefaultAirQualityBaseURL, DefaultAirQualityDays),
+	}
+}
+
+// Name implements AirQualityProvider.
+func (c *AirQualityClient) Name() string {
+	return c.api.name
+}
+
+// Breakers implements BreakerReporter.
+func (c *AirQualityClient) Breakers() []*CircuitBreaker {
+	return []*CircuitBreaker{c.api.upstream.breaker}
+}
+
+// FetchHourlyAirQuality implements AirQualityProvider. Hours for which the
+// API reports no values at all, typically at the end of the forecast, are left out.
+func (c *AirQualityClient) FetchHourlyAirQuality(ctx context.Context, lat, long float64) ([]model.HourlyAirQuality, error) {
+	raw, err := c.FetchAirQualityData(ctx, lat, long)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHourly(c.api.name, raw.Hourly); err != nil {
+		return nil, err
+	}
+
+	var result []model.HourlyAirQuality
+	hourly := raw.Hourly
+
+	for i, t := range hourly.Time {
+		parsed, err := iso8601.ParseString(t)
+		if err != nil {
+			logger.Log.Error("Failed to parse time", zap.String("time", t), zap.Error(err))
+			continue
+		}
+
+		values := []*float64{hourly.PM25[i], hourly.PM10[i], hourly.Ozone[i], hourly.EuropeanAQI[i], hourly.USAQI[i]}
+		if !slices.ContainsFunc(values, func(v *float64) bool { return v != nil }) {
+			continue
+		}
+
+		result = append(result, model.HourlyAirQuality{
+			Time: parsed.UTC(),
+			AirQuality: model.AirQuality{
+				PM25:        valueOrZero(hourly.PM25[i]),
+				PM10:        valueOrZero(hourly.PM10[i]),
+				Ozone:       valueOrZero(hourly.Ozone[i]),
+				EuropeanAQI: int(math.Round(valueOrZero(hourly.EuropeanAQI[i]))),
+				USAQI:       int(math.Round(valueOrZero(hourly.USAQI[i]))),
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// FetchAirQualityData retrieves the air quality forecast from the Open-Meteo
+// Air Quality API for the specified latitude and longitude. Payloads are served
+// from the payload store when one is configured.
+func (c *AirQualityClient) FetchAirQualityData(ctx context.Context, lat, long float64) (*model.AirQualityResponse, error) {
+	return fetchHourly[model.AirQualityResponse](ctx, c.api, lat, long, airQualityHourly, nil)
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const airQualityPayload = `{"hourly":{
+	"time":["2026-06-01T00:00","2026-06-01T01:00"],
+	"pm2_5":[8.1,9.4],"pm10":[12.5,14],"ozone":[61,58],
+	"european_aqi":[24,27],"us_aqi":[33,null]}}`
+
+func TestAirQualityTruncatedPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Replace(airQualityPayload, `"ozone":[61,58]`, `"ozone":[61]`, 1)))
+	}))
+	defer srv.Close()
+
+	c := NewAirQualityClient(AirQualityOptions{OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL}})
+
+	_, err := c.FetchHourlyAirQuality(context.Background(), 52.52, 13.41)
+	if err == nil || !strings.Contains(err.Error(), "hourly ozone has 1 values for 2 time steps") {
+		t.Errorf("err = %v, want a truncated ozone series", err)
+	}
+}
+
+func TestAirQualityDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hourly := r.URL.Query().Get("hourly"); hourly != strings.Join(airQualityHourly, ",") {
+			t.Errorf("hourly = %q", hourly)
+		}
+		w.Write([]byte(airQualityPayload))
+	}))
+	defer srv.Close()
+
+	c := NewAirQualityClient(AirQualityOptions{OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL}})
+
+	hours, err := c.FetchHourlyAirQuality(context.Background(), 52.52, 13.41)
+	if err != nil {
+		t.Fatalf("FetchHourlyAirQuality: %v", err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("got %d hours, want 2", len(hours))
+	}
+
+	want := time.Date(2026, 6, 1, 1, 0, 0, 0, time.UTC)
+	if !hours[1].Time.Equal(want) {
+		t.Errorf("hours[1].Time = %v, want %v", hours[1].Time, want)
+	}
+	if aq := hours[0].AirQuality; aq.PM25 != 8.1 || aq.PM10 != 12.5 || aq.Ozone != 61 || aq.EuropeanAQI != 24 || aq.USAQI != 33 {
+		t.Errorf("hours[0].AirQuality = %+v", aq)
+	}
+	if got := hours[1].AirQuality.USAQI; got != 0 {
+		t.Errorf("hours[1].AirQuality.USAQI = %v, want 0 for a null value", got)
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// ErrCircuitOpen is returned without calling upstream while a provider's circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type BreakerState string
+
+// States of a circuit breaker
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// Defaults for BreakerOptions
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerCooldown         = 30 * time.Second
+)
+
+// BreakerOptions configures a CircuitBreaker. Zero values fall back to the defaults.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed calls that opens the breaker.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before letting a trial call through.
+	Cooldown time.Duration
+}
+
+// CircuitBreaker fails calls fast while an upstream provider is down.
+//
+// After FailureThreshold consecutive failures the breaker opens and rejects calls
+// for the cooldown period. It then lets a single trial call through (half-open):
+// a success closes the breaker again, a failure re-opens it.
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func NewCircuitBreaker(name string, opts BreakerOptions) *CircuitBreaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultBreakerFailureThreshold
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultBreakerCooldown
+	}
+
+	return &CircuitBreaker{
+		name:      name,
+		threshold: opts.FailureThreshold,
+		cooldown:  opts.Cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. It returns an error wrapping
+// ErrCircuitOpen when the call must fail fast.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		retryAt := b.openedAt.Add(b.cooldown)
+		if time.Now().Before(retryAt) {
+			return fmt.Errorf("%s: %w until %s", b.name, ErrCircuitOpen, retryAt.UTC().Format(time.RFC3339))
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+	case BreakerHalfOpen:
+		if b.trial {
+			return fmt.Errorf("%s: %w, trial call in progress", b.name, ErrCircuitOpen)
+		}
+		b.trial = true
+	}
+
+	return nil
+}
+
+// Record reports the outcome of an allowed call.
+func (b *CircuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release ends an allowed call whose outcome is unknown, e.g. because the caller gave up.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Status returns a snapshot of the breaker state.
+func (b *CircuitBreaker) Status() model.BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := model.BreakerStatus{
+		Provider:            b.name,
+		State:               string(b.state),
+		ConsecutiveFailures: b.failures,
+	}
+
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt.UTC()
+		retryAt := openedAt.Add(b.cooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
+
+// BreakerReporter is implemented by providers guarded by circuit breakers,
+// including wrappers that forward the breakers of the providers they wrap.
+type BreakerReporter interface {
+	Breakers() []*CircuitBreaker
+}
+
+// breakersOf returns the circuit breakers of the provider, if it reports any.
+func breakersOf(p any) []*CircuitBreaker {
+	if r, ok := p.(BreakerReporter); ok {
+		return r.Breakers()
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// Defaults for CacheOptions
+const (
+	// DefaultCacheResolution roughly matches the ~11 km grid of the global forecast models.
+	DefaultCacheResolution = 0.1
+	// DefaultModelCadence matches the hourly update cycle of the Open-Meteo forecast API.
+	DefaultModelCadence = 1 * time.Hour
+	// DefaultCacheFetchTimeout matches the time limit of a forecast request.
+	DefaultCacheFetchTimeout = 1 * time.Minute
+)
+
+// CacheOptions configures a CachedProvider. Zero values fall back to the defaults.
+type CacheOptions struct {
+	// Resolution is the grid resolution in degrees that coordinates are rounded to.
+	Resolution float64
+	// ModelCadence is the interval between upstream model runs. Cached forecasts
+	// expire when the next run becomes available.
+	ModelCadence time.Duration
+	// FetchTimeout bounds an upstream fetch, which is shared by the callers
+	// that missed the cache meanwhile and so outlives their own deadlines.
+	FetchTimeout time.Duration
+}
+
+// CachedProvider is an in-process cache in front of a WeatherProvider.
+//
+// Forecasts are keyed by the coordinates rounded to the grid resolution and by
+// the current model run, so nearby venues share one upstream call and entries
+// expire together with the model run they were fetched for. Concurrent misses
+// for the same key are coalesced into a single upstream request.
+type CachedProvider struct {
+	provider WeatherProvider
+	cache    *gridCache[[]model.HourlyForecast]
+}
+
+func NewCachedProvider(provider WeatherProvider, opts CacheOptions) *CachedProvider {
+	return &CachedProvider{
+		provider: provider,
+		cache:    newGridCache[[]model.HourlyForecast](opts),
+	}
+}
+
+// Name implements WeatherProvider.
+func (c *CachedProvider) Name() string {
+	return c.provider.Name()
+}
+
+// Breakers implements BreakerReporter.
+func (c *CachedProvider) Breakers() []*CircuitBreaker {
+	return breakersOf(c.provider)
+}
+
+// FetchHourlyForecast implements WeatherProvider. The returned slice is shared
+// between callers and must not be modified.
+func (c *CachedProvider) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	return c.cache.get(ctx, c.provider.Name(), lat, long, c.provider.FetchHourlyForecast)
+}
+
+// CachedAirQualityProvider is an in-process cache in front of an
+// AirQualityProvider, keyed and expiring like a CachedProvider.
+type CachedAirQualityProvider struct {
+	provider AirQualityProvider
+	cache    *gridCache[[]model.HourlyAirQuality]
+}
+
+func NewCachedAirQualityProvider(provider AirQualityProvider, opts CacheOptions) *CachedAirQualityProvider {
+	return &CachedAirQualityProvider{
+		provider: provider,
+		cache:    newGridCache[[]model.HourlyAirQuality](opts),
+	}
+}
+
+// Name implements AirQualityProvider.
+func (c *CachedAirQualityProvider) Name() string {
+	return c.provider.Name()
+}
+
+// Breakers implements BreakerReporter.
+func (c *CachedAirQualityProvider) Breakers() []*CircuitBreaker {
+	return breakersOf(c.provider)
+}
+
+// FetchHourlyAirQuality implements AirQualityProvider. The returned slice is
+// shared between callers and must not be modified.
+func (c *CachedAirQualityProvider) FetchHourlyAirQuality(ctx context.Context, lat, long float64) ([]model.HourlyAirQuality, error) {
+	return c.cache.get(ctx, c.provider.Name(), lat, long, c.provider.FetchHourlyAirQuality)
+}
+
+// CachedMarineProvider is an in-process cache in front of a MarineProvider,
+// keyed and expiring like a CachedProvider.
+type CachedMarineProvider struct {
+	provider MarineProvider
+	cache    *gridCache[[]model.HourlyMarine]
+}
+
+func NewCachedMarineProvider(provider MarineProvider, opts CacheOptions) *CachedMarineProvider {
+	return &CachedMarineProvider{
+		provider: provider,
+		cache:    newGridCache[[]model.HourlyMarine](opts),
+	}
+}
+
+// Name implements MarineProvider.
+func (c *CachedMarineProvider) Name() string {
+	return c.provider.Name()
+}
+
+// Breakers implements BreakerReporter.
+func (c *CachedMarineProvider) Breakers() []*CircuitBreaker {
+	return breakersOf(c.provider)
+}
+
+// FetchHourlyMarine implements MarineProvider. The returned slice is shared
+// between callers and must not be modified.
+func (c *CachedMarineProvider) FetchHourlyMarine(ctx context.Context, lat, long float64) ([]model.HourlyMarine, error) {
+	return c.cache.get(ctx, c.provider.Name(), lat, long, c.provider.FetchHourlyMarine)
+}
+
+// gridCache caches upstream results by grid cell and model run.
+type gridCache[T any] struct {
+	resolution float64
+	cadence    time.Duration
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry[T]
+	group   singleflight.Group
+}
+
+type cacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newGridCache[T any](opts CacheOptions) *gridCache[T] {
+	if opts.Resolution <= 0 {
+		opts.Resolution = DefaultCacheResolution
+	}
+	if opts.ModelCadence <= 0 {
+		opts.ModelCadence = DefaultModelCadence
+	}
+	if opts.FetchTimeout <= 0 {
+		opts.FetchTimeout = DefaultCacheFetchTimeout
+	}
+
+	return &gridCache[T]{
+		resolution: opts.Resolution,
+		cadence:    opts.ModelCadence,
+		timeout:    opts.FetchTimeout,
+		now:        time.Now,
+		entries:    make(map[string]cacheEntry[T]),
+	}
+}
+
+// get returns the cached result for the grid cell of the coordinates, calling
+// fetch with the coordinates of the cell on a miss.
+func (c *gridCache[T]) get(
+	ctx context.Context,
+	name string,
+	lat, long float64,
+	fetch func(ctx context.Context, lat, long float64) (T, error),
+) (T, error) {
+	now := c.now().UTC()
+	run := now.Truncate(c.cadence)
+	lat, long = c.round(lat), c.round(long)
+	key := fmt.Sprintf("%s|%.4f,%.4f|%d", name, lat, long, run.Unix())
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	// The shared fetch must not be cancelled by whichever caller started it
+	ch := c.group.DoChan(key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+
+		value, err := fetch(fetchCtx, lat, long)
+		if err != nil {
+			return nil, err
+		}
+
+		c.store(now, key, cacheEntry[T]{value: value, expiresAt: run.Add(c.cadence)})
+		return value, nil
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
+
+// store saves an entry and evicts the entries of previous model runs.
+func (c *gridCache[T]) store(now time.Time, key string, entry cacheEntry[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// round snaps a coordinate to the cache grid.
+func (c *gridCache[T]) round(v float64) float64 {
+	return math.Round(v/c.resolution) * c.resolution
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// countingAirQuality is an AirQualityProvider counting its calls.
+type countingAirQuality struct {
+	calls atomic.Int32
+}
+
+func (p *countingAirQuality) Name() string {
+	return "counting"
+}
+
+func (p *countingAirQuality) FetchHourlyAirQuality(ctx context.Context, lat, long float64) ([]model.HourlyAirQuality, error) {
+	p.calls.Add(1)
+	return []model.HourlyAirQuality{{Time: time.Now().UTC().Truncate(time.Hour)}}, nil
+}
+
+func TestCachedAirQualityProvider(t *testing.T) {
+	provider := &countingAirQuality{}
+	c := NewCachedAirQualityProvider(provider, CacheOptions{})
+
+	// Both venues lie in the same 0.1° grid cell
+	for _, loc := range [][2]float64{{52.52, 13.41}, {52.51, 13.38}} {
+		hours, err := c.FetchHourlyAirQuality(context.Background(), loc[0], loc[1])
+		if err != nil {
+			t.Fatalf("FetchHourlyAirQuality: %v", err)
+		}
+		if len(hours) != 1 {
+			t.Errorf("got %d hours, want 1", len(hours))
+		}
+	}
+	if got := provider.calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+
+	if _, err := c.FetchHourlyAirQuality(context.Background(), 48.86, 2.35); err != nil {
+		t.Fatalf("FetchHourlyAirQuality: %v", err)
+	}
+	if got := provider.calls.Load(); got != 2 {
+		t.Errorf("provider called %d times for another grid cell, want 2", got)
+	}
+}
+
+// funcProvider is a WeatherProvider calling fetch, counting its calls.
+type funcProvider struct {
+	calls atomic.Int32
+	fetch func(ctx context.Context, call int32) ([]model.HourlyForecast, error)
+}
+
+func (p *funcProvider) Name() string {
+	return "func"
+}
+
+func (p *funcProvider) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	return p.fetch(ctx, p.calls.Add(1))
+}
+
+func TestCachedProviderCoalescesCallers(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	provider := &funcProvider{fetch: func(ctx context.Context, call int32) ([]model.HourlyForecast, error) {
+		if call == 1 {
+			close(started)
+		}
+		<-release
+		return []model.HourlyForecast{{WindKmh: 12}}, nil
+	}}
+	c := NewCachedProvider(provider, CacheOptions{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := range 10 {
+		wg.Go(func() {
+			// Every venue lies in the same 0.1° grid cell
+			hours, err := c.FetchHourlyForecast(context.Background(), 52.52+float64(i)/1000, 13.41)
+			if err == nil && (len(hours) != 1 || hours[0].WindKmh != 12) {
+				err = fmt.Errorf("hours = %+v", hours)
+			}
+			errs <- err
+		})
+	}
+
+	<-started
+	time.Sleep(20 * time.Millisecond) // Let the other callers join the fetch in progress
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := provider.calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+}
+
+func TestCachedProviderModelRuns(t *testing.T) {
+	provider := &funcProvider{fetch: func(ctx context.Context, call int32) ([]model.HourlyForecast, error) {
+		return []model.HourlyForecast{{WindKmh: float64(call)}}, nil
+	}}
+	c := NewCachedProvider(provider, CacheOptions{})
+
+	run := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want float64
+	}{
+		{now: run.Add(5 * time.Minute), want: 1},
+		{now: run.Add(55 * time.Minute), want: 1},
+		// The next model run is fetched again
+		{now: run.Add(65 * time.Minute), want: 2},
+		{now: run.Add(90 * time.Minute), want: 2},
+	}
+
+	for _, tt := range tests {
+		c.cache.now = func() time.Time { return tt.now }
+
+		hours, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41)
+		if err != nil {
+			t.Fatalf("at %v: %v", tt.now, err)
+		}
+		if got := hours[0].WindKmh; got != tt.want {
+			t.Errorf("at %v: got the forecast of call %v, want call %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestCachedProviderErrorsNotCached(t *testing.T) {
+	provider := &funcProvider{fetch: func(ctx context.Context, call int32) ([]model.HourlyForecast, error) {
+		if call == 1 {
+			return nil, errors.New("upstream down")
+		}
+		return []model.HourlyForecast{{WindKmh: 12}}, nil
+	}}
+	c := NewCachedProvider(provider, CacheOptions{})
+
+	if _, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41); err == nil {
+		t.Fatal("expected the upstream error")
+	}
+	if _, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41); err != nil {
+		t.Fatalf("FetchHourlyForecast after an error: %v", err)
+	}
+	if got := provider.calls.Load(); got != 2 {
+		t.Errorf("provider called %d times, want 2", got)
+	}
+}
+
+func TestCachedProviderFetchTimeout(t *testing.T) {
+	provider := &funcProvider{fetch: func(ctx context.Context, call int32) ([]model.HourlyForecast, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	c := NewCachedProvider(provider, CacheOptions{FetchTimeout: 10 * time.Millisecond})
+
+	// The caller sets no deadline, yet the shared fetch is bounded
+	_, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type FixtureMode string
+
+// Modes of a FixtureTransport
+const (
+	// FixtureRecord forwards requests upstream and saves every response as a fixture.
+	FixtureRecord FixtureMode = "record"
+	// FixtureReplay serves responses from fixtures and never calls upstream.
+	FixtureReplay FixtureMode = "replay"
+)
+
+// ErrFixtureNotFound is returned in replay mode for requests that were never recorded.
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+// fixture is the on-disk representation of a recorded upstream response.
+type fixture struct {
+	// Comment optionally describes the fixture, e.g. that it was written by
+	// hand rather than recorded. It is ignored on replay.
+	Comment string `json:"comment,omitempty"`
+	Method  string `json:"method"`
+	URL     string `json:"url"`
+	Status  int    `json:"status"`
+	// Headers only keeps the response headers the clients rely on.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body holds JSON payloads as is, so that fixtures stay readable and diffable.
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"body_text,omitempty"`
+	// RecordedAt is when the response was recorded.
+	RecordedAt time.Time `json:"recorded_at,omitzero"`
+}
+
+// fixtureHeaders lists the response headers saved in fixtures.
+var fixtureHeaders = []string{"Content-Type", "Retry-After"}
+
+// FixtureTransport is an http.RoundTripper that records upstream responses to
+// fixture files, or replays them, for reproducible runs that do not depend on
+// live weather.
+//
+// Fixtures are keyed by request method and URL, with any API key left out, and
+// stored as one JSON file per request in the fixture directory.
+type FixtureTransport struct {
+	mode FixtureMode
+	dir  string
+	next http.RoundTripper
+}
+
+// NewFixtureTransport creates a transport in the given mode. In record mode,
+// requests are forwarded to next, or to http.DefaultTransport when next is nil.
+func NewFixtureTransport(mode FixtureMode, dir string, next http.RoundTripper) (*FixtureTransport, error) {
+	switch mode {
+	case FixtureRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	case FixtureReplay:
+		if _, err := os.Stat(dir); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown fixture mode %q", mode)
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &FixtureTransport{
+		mode: mode,
+		dir:  dir,
+		next: next,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := fixtureKey(req)
+	path := filepath.Join(t.dir, fixtureName(req, key))
+
+	if t.mode == FixtureReplay {
+		return t.replay(req, key, path)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.record(req, key, path, resp, body); err != nil {
+		return nil, fmt.Errorf("recording fixture %s: %w", path, err)
+	}
+
+	return resp, nil
+}
+
+func (t *FixtureTransport) record(req *http.Request, key, path string, resp *http.Response, body []byte) error {
+	f := fixture{
+		Method:     req.Method,
+		URL:        key,
+		Status:     resp.StatusCode,
+		Headers:    make(map[string]string),
+		RecordedAt: time.Now().UTC(),
+	}
+
+	for _, h := range fixtureHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			f.Headers[h] = v
+		}
+	}
+
+	if json.Valid(body) {
+		f.Body = body
+	} else {
+		f.BodyText = string(body)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func (t *FixtureTransport) replay(req *http.Request, key, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s", ErrFixtureNotFound, req.Method, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	body := []byte(f.BodyText)
+	if len(f.Body) > 0 {
+		body = f.Body
+	}
+
+	header := make(http.Header)
+	for k, v := range f.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// RecordedAt returns when the last fixture of the directory was recorded, or
+// the zero time if there is none. Replays run at that time see the forecasts
+// as they were when recorded, such as which events were still ahead.
+func (t *FixtureTransport) RecordedAt() (time.Time, error) {
+	paths, err := filepath.Glob(filepath.Join(t.dir, "*.json"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		var f fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return time.Time{}, fmt.Errorf("invalid fixture %s: %w", path, err)
+		}
+		if f.RecordedAt.After(latest) {
+			latest = f.RecordedAt
+		}
+	}
+
+	return latest, nil
+}
+
+// fixtureKey identifies a request by its URL with sorted query parameters,
+// leaving out the API key so that it never ends up in a fixture.
+func fixtureKey(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	q.Del("apikey")
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// fixtureName derives a stable file name from the upstream host and a hash of the request.
+func fixtureName(req *http.Request, key string) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + key))
+	host := strings.NewReplacer(":", "_", "/", "_").Replace(req.URL.Host)
+
+	return fmt.Sprintf("%s_%s.json", host, hex.EncodeToString(sum[:8]))
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+
+	"github.com/relvacode/iso8601"
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// Defaults for MarineOptions
+const (
+	// DefaultMarineBaseURL points at the free Open-Meteo Marine API.
+	DefaultMarineBaseURL = "https://marine-api.open-meteo.com/v1/marine"
+	DefaultMarineDays    = 7
+)
+
+// marineHourly lists the hourly variables required to build model.HourlyMarine.
+var marineHourly = []string{"wave_height", "wave_period", "swell_wave_height", "swell_wave_period", "swell_wave_direction"}
+
+// MarineProvider is implemented by upstream sea state sources.
+type MarineProvider interface {
+	// Name identifies the provider in logs.
+	Name() string
+	// FetchHourlyMarine returns the hourly sea state forecast for the
+	// location, ordered by time and expressed in UTC.
+	FetchHourlyMarine(ctx context.Context, lat, long float64) ([]model.HourlyMarine, error)
+}
+
+// MarineOptions configures a MarineClient. Zero values fall back to the defaults.
+type MarineOptions struct {
+	OpenMeteoEndpoint
+}
+
+// MarineClient fetches wave and swell forecasts from the Open-Meteo Marine API.
+type MarineClient struct {
+	api *openMeteoAPI
+}
+
+func NewMarineClient(opts MarineOptions) *MarineClient {
+	return &MarineClient{
+		api: newOpenMeteoAPI("open-meteo-marine", opts.OpenMeteoEndpoint, DefaultMarineBaseURL, DefaultMarineDays),
+	}
+}
+
+// Name implements MarineProvider.
+func (c *MarineClient) Name() string {
+	return c.api.name
+}
+
+// Breakers implements BreakerReporter.
+func (c *MarineClient) Breakers() []*CircuitBreaker {
+	return []*CircuitBreaker{c.api.upstream.breaker}
+}
+
+// FetchHourlyMarine implements MarineProvider. Hours without a wave forecast,
+// such as every hour of an inland location, are left out.
+func (c *MarineClient) FetchHourlyMarine(ctx context.Context, lat, long float64) ([]model.HourlyMarine, error) {
+	raw, err := c.FetchMarineData(ctx, lat, long)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHourly(c.api.name, raw.Hourly); err != nil {
+		return nil, err
+	}
+
+	var result []model.HourlyMarine
+	hourly := raw.Hourly
+
+	for i, t := range hourly.Time {
+		parsed, err := iso8601.ParseString(t)
+		if err != nil {
+			logger.Log.Error("Failed to parse time", zap.String("time", t), zap.Error(err))
+			continue
+		}
+
+		if hourly.WaveHeight[i] == nil {
+			continue
+		}
+
+		result = append(result, model.HourlyMarine{
+			Time: parsed.UTC(),
+			Marine: model.Marine{
+				WaveHeightM:    valueOrZero(hourly.WaveHeight[i]),
+				WavePeriodS:    valueOrZero(hourly.WavePeriod[i]),
+				SwellHeightM:   valueOrZero(hourly.SwellWaveHeight[i]),
+				SwellPeriodS:   valueOrZero(hourly.SwellWavePeriod[i]),
+				SwellDirection: valueOrZero(hourly.SwellWaveDirection[i]),
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// FetchMarineData retrieves the marine forecast from the Open-Meteo Marine API
+// for the specified latitude and longitude. Payloads are served from the
+// payload store when one is configured.
+func (c *MarineClient) FetchMarineData(ctx context.Context, lat, long float64) (*model.MarineResponse, error) {
+	return fetchHourly[model.MarineResponse](ctx, c.api, lat, long, marineHourly, nil)
+}
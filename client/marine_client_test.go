@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+const marinePayload = `{"hourly":{
+	"time":["2026-06-01T00:00","2026-06-01T01:00"],
+	"wave_height":[1.2,null],"wave_period":[6.5,null],"swell_wave_height":[0.8,null],
+	"swell_wave_period":[9.1,null],"swell_wave_direction":[275,null]}}`
+
+func TestMarineTruncatedPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Replace(marinePayload, `"wave_period":[6.5,null]`, `"wave_period":[6.5]`, 1)))
+	}))
+	defer srv.Close()
+
+	c := NewMarineClient(MarineOptions{OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL}})
+
+	_, err := c.FetchHourlyMarine(context.Background(), 54.32, 10.14)
+	if err == nil || !strings.Contains(err.Error(), "hourly wave_period has 1 values for 2 time steps") {
+		t.Errorf("err = %v, want a truncated wave_period series", err)
+	}
+}
+
+func TestMarineDecode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hourly := r.URL.Query().Get("hourly"); hourly != strings.Join(marineHourly, ",") {
+			t.Errorf("hourly = %q", hourly)
+		}
+		w.Write([]byte(marinePayload))
+	}))
+	defer srv.Close()
+
+	c := NewMarineClient(MarineOptions{OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL}})
+
+	hours, err := c.FetchHourlyMarine(context.Background(), 54.32, 10.14)
+	if err != nil {
+		t.Fatalf("FetchHourlyMarine: %v", err)
+	}
+
+	// The hour without a wave forecast is left out
+	want := model.HourlyMarine{
+		Time: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Marine: model.Marine{
+			WaveHeightM:    1.2,
+			WavePeriodS:    6.5,
+			SwellHeightM:   0.8,
+			SwellPeriodS:   9.1,
+			SwellDirection: 275,
+		},
+	}
+	if len(hours) != 1 || !hours[0].Time.Equal(want.Time) || hours[0].Marine != want.Marine {
+		t.Errorf("hours = %+v, want [%+v]", hours, want)
+	}
+}
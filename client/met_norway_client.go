@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/relvacode/iso8601"
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+const (
+	// DefaultMetNorwayBaseURL points at the public Locationforecast 2.0 API.
+	DefaultMetNorwayBaseURL = "https://api.met.no/weatherapi/locationforecast/2.0"
+	// DefaultMetNorwayUserAgent identifies the service as MET Norway's terms of service require.
+	DefaultMetNorwayUserAgent = "EventWeatherGuard/1.0 github.com/ihgazi/EventWeatherGuard"
+)
+
+// MetNorwayOptions configures a MetNorwayClient. Zero values fall back to the defaults.
+type MetNorwayOptions struct {
+	BaseURL   string
+	UserAgent string
+	// Upstream configures retries and the circuit breaker.
+	Upstream UpstreamOptions
+}
+
+// MetNorwayClient fetches forecasts from MET Norway's Locationforecast 2.0 compact endpoint.
+type MetNorwayClient struct {
+	upstream  *upstream
+	baseURL   string
+	userAgent string
+}
+
+func NewMetNorwayClient(opts MetNorwayOptions) *MetNorwayClient {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultMetNorwayBaseURL
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultMetNorwayUserAgent
+	}
+
+	return &MetNorwayClient{
+		upstream:  newUpstream("met-norway", opts.Upstream),
+		baseURL:   strings.TrimRight(opts.BaseURL, "/"),
+		userAgent: opts.UserAgent,
+	}
+}
+
+// Name implements WeatherProvider.
+func (c *MetNorwayClient) Name() string {
+	return "met-norway"
+}
+
+// Breakers implements BreakerReporter.
+func (c *MetNorwayClient) Breakers() []*CircuitBreaker {
+	return []*CircuitBreaker{c.upstream.breaker}
+}
+
+// metNorwayMissing lists the fields the compact product does not carry.
+var metNorwayMissing = []string{
+	"rain_prob", "gust_kmh", "apparent_temp_c", "snowfall_cm", "snow_depth_cm",
+	"visibility_m", "cape_jkg", "lifted_index", "uv_index",
+}
+
+// FetchHourlyForecast implements WeatherProvider.
+//
+// The compact product does not carry a precipitation probability, an apparent
+// temperature, wind gusts, snow amounts, visibility, instability or UV index,
+// so those fields are left at zero and reported missing. Six-hourly steps at
+// the end of the series are spread over the hours up to the next step, with
+// the precipitation amount divided evenly.
+func (c *MetNorwayClient) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	raw, err := c.FetchWeatherData(ctx, lat, long)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.HourlyForecast
+	series := raw.Properties.Timeseries
+
+	for i, step := range series {
+		parsed, err := iso8601.ParseString(step.Time)
+		if err != nil {
+			logger.Log.Error("Failed to parse time", zap.String("time", step.Time), zap.Error(err))
+			continue
+		}
+		parsed = parsed.UTC()
+
+		// Number of hours until the next step, at most one 6-hour period
+		gap := 6
+		if i+1 < len(series) {
+			if next, err := iso8601.ParseString(series[i+1].Time); err == nil {
+				gap = max(1, min(6, int(next.Sub(parsed).Hours())))
+			}
+		}
+
+		period, hours, span := step.Data.Next1Hours, 1, 1.0
+		if (gap > 1 || period == nil) && step.Data.Next6Hours != nil {
+			period, hours, span = step.Data.Next6Hours, gap, 6.0
+		}
+		if period == nil {
+			continue
+		}
+
+		code := metSymbolToWMO(period.Summary.SymbolCode)
+
+		for h := range hours {
+			result = append(result, model.HourlyForecast{
+				Time:          parsed.Add(time.Duration(h) * time.Hour),
+				Precipitation: period.Details.PrecipitationAmount / span,
+				WindKmh:       msToKmh(step.Data.Instant.Details.WindSpeed),
+				WeatherCode:   code,
+				Weather:       model.LookupWMO(code).Label,
+				TemperatureC:  step.Data.Instant.Details.AirTemperature,
+				HumidityPct:   step.Data.Instant.Details.RelativeHumidity,
+				Missing:       metNorwayMissing,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// FetchWeatherData retrieves the Locationforecast compact document for the
+// specified latitude and longitude.
+func (c *MetNorwayClient) FetchWeatherData(ctx context.Context, lat, long float64) (*model.MetNorwayResponse, error) {
+	// MET Norway rejects coordinates with more than 4 decimals
+	url := fmt.Sprintf("%s/compact?lat=%.4f&lon=%.4f", c.baseURL, lat, long)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.upstream.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("met-norway returned status %d", resp.StatusCode)
+	}
+
+	var data model.MetNorwayResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// msToKmh converts a wind speed from m/s to km/h, rounded to one decimal.
+func msToKmh(ms float64) float64 {
+	return math.Round(ms*36) / 10
+}
+
+// metSymbols maps MET Norway symbol codes, without their day/night suffix, to
+// the closest WMO weather code.
+var metSymbols = map[string]int{
+	"clearsky":          0,
+	"fair":              1,
+	"partlycloudy":      2,
+	"cloudy":            3,
+	"fog":               45,
+	"lightrain":         61,
+	"rain":              63,
+	"heavyrain":         65,
+	"lightrainshowers":  80,
+	"rainshowers":       81,
+	"heavyrainshowers":  82,
+	"lightsleet":        68,
+	"sleet":             69,
+	"heavysleet":        69,
+	"lightsleetshowers": 68,
+	"sleetshowers":      69,
+	"heavysleetshowers": 69,
+	"lightsnow":         71,
+	"snow":              73,
+	"heavysnow":         75,
+	"lightsnowshowers":  85,
+	"snowshowers":       85,
+	"heavysnowshowers":  86,
+}
+
+// Map MET Norway symbol codes (e.g. "heavyrainandthunder", "lightrainshowers_day")
+// to WMO weather codes
+func metSymbolToWMO(symbol string) int {
+	symbol, _, _ = strings.Cut(symbol, "_")
+
+	if strings.Contains(symbol, "thunder") {
+		return 95
+	}
+
+	return metSymbols[symbol]
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// serveFile returns a server responding to every request with the content of
+// a file, passing each request to check first.
+func serveFile(t *testing.T, path string, check func(r *http.Request)) *httptest.Server {
+	t.Helper()
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestMetNorwayFetchHourlyForecast(t *testing.T) {
+	srv := serveFile(t, "testdata/met_norway_compact.json", func(r *http.Request) {
+		if r.URL.Path != "/compact" {
+			t.Errorf("path = %q, want /compact", r.URL.Path)
+		}
+		if got := r.URL.RawQuery; got != "lat=59.9139&lon=10.7522" {
+			t.Errorf("query = %q, want coordinates with 4 decimals", got)
+		}
+		if got := r.Header.Get("User-Agent"); got != DefaultMetNorwayUserAgent {
+			t.Errorf("User-Agent = %q, want %q", got, DefaultMetNorwayUserAgent)
+		}
+	})
+
+	c := NewMetNorwayClient(MetNorwayOptions{BaseURL: srv.URL})
+
+	hours, err := c.FetchHourlyForecast(context.Background(), 59.913868, 10.752245)
+	if err != nil {
+		t.Fatalf("FetchHourlyForecast: %v", err)
+	}
+
+	// Two hourly steps, then two 6-hourly steps; the last step has no period
+	if len(hours) != 14 {
+		t.Fatalf("got %d hours, want 14", len(hours))
+	}
+
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i, h := range hours {
+		if want := start.Add(time.Duration(i) * time.Hour); !h.Time.Equal(want) {
+			t.Errorf("hours[%d].Time = %v, want %v", i, h.Time, want)
+		}
+		if h.Time.Location() != time.UTC {
+			t.Errorf("hours[%d].Time is in %v, want UTC", i, h.Time.Location())
+		}
+	}
+
+	tests := []struct {
+		i           int
+		precip      float64
+		windKmh     float64
+		code        int
+		tempC       float64
+		humidityPct float64
+	}{
+		// Hourly steps use next_1_hours
+		{i: 0, precip: 0.2, windKmh: 14.8, code: 2, tempC: 18.3, humidityPct: 61.2},
+		{i: 1, precip: 1.5, windKmh: 18, code: 63, tempC: 18.9, humidityPct: 66},
+		// Followed by a 6-hour gap, so next_6_hours is spread over the hours up to the next step
+		{i: 2, precip: 0.5, windKmh: 27, code: 95, tempC: 17.6, humidityPct: 78.4},
+		{i: 7, precip: 0.5, windKmh: 27, code: 95, tempC: 17.6, humidityPct: 78.4},
+		// 6-hourly tail
+		{i: 8, precip: 0.2, windKmh: 11.5, code: 3, tempC: 14.2, humidityPct: 84.5},
+		{i: 13, precip: 0.2, windKmh: 11.5, code: 3, tempC: 14.2, humidityPct: 84.5},
+	}
+
+	for _, tt := range tests {
+		h := hours[tt.i]
+		if math.Abs(h.Precipitation-tt.precip) > 1e-9 {
+			t.Errorf("hours[%d].Precipitation = %v, want %v", tt.i, h.Precipitation, tt.precip)
+		}
+		if h.WindKmh != tt.windKmh {
+			t.Errorf("hours[%d].WindKmh = %v, want %v", tt.i, h.WindKmh, tt.windKmh)
+		}
+		if h.WeatherCode != tt.code {
+			t.Errorf("hours[%d].WeatherCode = %d, want %d", tt.i, h.WeatherCode, tt.code)
+		}
+		if h.TemperatureC != tt.tempC {
+			t.Errorf("hours[%d].TemperatureC = %v, want %v", tt.i, h.TemperatureC, tt.tempC)
+		}
+		if h.HumidityPct != tt.humidityPct {
+			t.Errorf("hours[%d].HumidityPct = %v, want %v", tt.i, h.HumidityPct, tt.humidityPct)
+		}
+		if h.Weather == "" {
+			t.Errorf("hours[%d].Weather is empty", tt.i)
+		}
+		if h.Reports("gust_kmh") || h.Reports("rain_prob") || !h.Reports("wind_kmh") {
+			t.Errorf("hours[%d].Missing = %v, want the fields the compact product lacks", tt.i, h.Missing)
+		}
+	}
+}
+
+func TestMetNorwayFetchHourlyForecastStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := NewMetNorwayClient(MetNorwayOptions{BaseURL: srv.URL})
+
+	if _, err := c.FetchHourlyForecast(context.Background(), 59.91, 10.75); err == nil {
+		t.Fatal("expected an error for a 403 response")
+	}
+}
@@ -0,0 +1,571 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relvacode/iso8601"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+const (
+	// DefaultNWSBaseURL points at the public National Weather Service API.
+	DefaultNWSBaseURL = "https://api.weather.gov"
+	// DefaultNWSUserAgent identifies the service as the NWS API requires.
+	DefaultNWSUserAgent = "EventWeatherGuard/1.0 github.com/ihgazi/EventWeatherGuard"
+	// DefaultNWSPointCacheSize is the number of resolved coordinates kept by default.
+	DefaultNWSPointCacheSize = 4096
+)
+
+// NWSOptions configures an NWSClient. Zero values fall back to the defaults.
+type NWSOptions struct {
+	BaseURL   string
+	UserAgent string
+	// PointCacheSize is the number of resolved coordinates kept, least recently used first out.
+	PointCacheSize int
+	// Upstream configures retries and the circuit breaker.
+	Upstream UpstreamOptions
+}
+
+// gridpoint identifies an NWS forecast grid cell.
+type gridpoint struct {
+	office string
+	x, y   int
+}
+
+// NWSClient fetches forecasts from the US National Weather Service.
+//
+// Coordinates are first resolved to a forecast gridpoint through /points. The
+// mapping never changes for a coordinate, so resolved gridpoints are kept in a
+// bounded LRU cache. Coordinates well outside the NWS coverage area are rejected
+// without calling /points, and unsupported regions are not cached, so arbitrary
+// coordinates cannot grow the cache beyond its size.
+type NWSClient struct {
+	upstream  *upstream
+	baseURL   string
+	userAgent string
+
+	mu         sync.Mutex
+	points     map[string]*list.Element
+	pointOrder *list.List // of pointEntry, most recently used first
+	pointLimit int
+}
+
+// pointEntry is a cached /points result.
+type pointEntry struct {
+	key  string
+	grid gridpoint
+}
+
+func NewNWSClient(opts NWSOptions) *NWSClient {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultNWSBaseURL
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultNWSUserAgent
+	}
+	if opts.PointCacheSize <= 0 {
+		opts.PointCacheSize = DefaultNWSPointCacheSize
+	}
+
+	return &NWSClient{
+		upstream:   newUpstream("nws", opts.Upstream),
+		baseURL:    strings.TrimRight(opts.BaseURL, "/"),
+		userAgent:  opts.UserAgent,
+		points:     make(map[string]*list.Element),
+		pointOrder: list.New(),
+		pointLimit: opts.PointCacheSize,
+	}
+}
+
+// Name implements WeatherProvider.
+func (c *NWSClient) Name() string {
+	return "nws"
+}
+
+// Breakers implements BreakerReporter.
+func (c *NWSClient) Breakers() []*CircuitBreaker {
+	return []*CircuitBreaker{c.upstream.breaker}
+}
+
+// nwsMissing lists the fields the gridpoint forecast does not carry.
+var nwsMissing = []string{"snow_depth_cm", "cape_jkg", "lifted_index", "uv_index"}
+
+// FetchHourlyForecast implements WeatherProvider.
+//
+// It returns an error wrapping ErrUnsupportedRegion for coordinates outside
+// the NWS coverage area.
+func (c *NWSClient) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	grid, err := c.resolveGridpoint(ctx, lat, long)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := c.fetchGridpointData(ctx, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	props := raw.Properties
+
+	precip, err := expandLayer(props.QuantitativePrecipitation, true)
+	if err != nil {
+		return nil, err
+	}
+	prob, err := expandLayer(props.ProbabilityOfPrecipitation, false)
+	if err != nil {
+		return nil, err
+	}
+	wind, err := expandLayer(props.WindSpeed, false)
+	if err != nil {
+		return nil, err
+	}
+	gust, err := expandLayer(props.WindGust, false)
+	if err != nil {
+		return nil, err
+	}
+	temp, err := expandLayer(props.Temperature, false)
+	if err != nil {
+		return nil, err
+	}
+	humidity, err := expandLayer(props.RelativeHumidity, false)
+	if err != nil {
+		return nil, err
+	}
+	apparent, err := expandLayer(props.ApparentTemperature, false)
+	if err != nil {
+		return nil, err
+	}
+	snowfall, err := expandLayer(props.SnowfallAmount, true)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := expandLayer(props.Visibility, false)
+	if err != nil {
+		return nil, err
+	}
+	sky, err := expandLayer(props.SkyCover, false)
+	if err != nil {
+		return nil, err
+	}
+	weather, err := expandWeatherLayer(props.Weather)
+	if err != nil {
+		return nil, err
+	}
+
+	windFactor, gustFactor := 1.0, 1.0
+	if strings.HasSuffix(props.WindSpeed.UOM, "m_s-1") {
+		windFactor = 3.6
+	}
+	if strings.HasSuffix(props.WindGust.UOM, "m_s-1") {
+		gustFactor = 3.6
+	}
+
+	// Layers may not cover every hour, e.g. precipitation amounts end before
+	// the rest of the forecast
+	layers := []struct {
+		field  string
+		values map[time.Time]float64
+	}{
+		{"rain_prob", prob},
+		{"precip_mm", precip},
+		{"gust_kmh", gust},
+		{"temp_c", temp},
+		{"humidity_pct", humidity},
+		{"apparent_temp_c", apparent},
+		{"snowfall_cm", snowfall},
+		{"visibility_m", visibility},
+	}
+
+	// Wind speed is forecast for every hour, so it drives the time axis
+	times := make([]time.Time, 0, len(wind))
+	for t := range wind {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	result := make([]model.HourlyForecast, 0, len(times))
+	for _, t := range times {
+		// Hours without any weather phenomenon are described by their sky cover
+		code, ok := weather[t]
+		if !ok {
+			code = skyCoverToWMO(sky[t])
+		}
+
+		missing := slices.Clone(nwsMissing)
+		for _, l := range layers {
+			if _, ok := l.values[t]; !ok {
+				missing = append(missing, l.field)
+			}
+		}
+
+		result = append(result, model.HourlyForecast{
+			Time:          t,
+			RainProb:      int(math.Round(prob[t])),
+			Precipitation: precip[t],
+			WindKmh:       math.Round(wind[t]*windFactor*10) / 10,
+			GustKmh:       math.Round(gust[t]*gustFactor*10) / 10,
+			WeatherCode:   code,
+			Weather:       model.LookupWMO(code).Label,
+			TemperatureC:  temp[t],
+			HumidityPct:   humidity[t],
+			ApparentTempC: apparent[t],
+			// Snowfall amounts are reported in mm of snow
+			SnowfallCm:  math.Round(snowfall[t]) / 10,
+			VisibilityM: visibility[t],
+			Missing:     missing,
+		})
+	}
+
+	return result, nil
+}
+
+// fetchGridpointData retrieves the raw forecast layers for a gridpoint.
+func (c *NWSClient) fetchGridpointData(ctx context.Context, grid gridpoint) (*model.NWSGridpointResponse, error) {
+	url := fmt.Sprintf("%s/gridpoints/%s/%d,%d", c.baseURL, grid.office, grid.x, grid.y)
+
+	var data model.NWSGridpointResponse
+	status, err := c.getJSON(ctx, url, &data)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("nws returned status %d", status)
+	}
+
+	return &data, nil
+}
+
+// resolveGridpoint maps a coordinate to its forecast gridpoint, consulting the cache first.
+func (c *NWSClient) resolveGridpoint(ctx context.Context, lat, long float64) (gridpoint, error) {
+	// The points endpoint only accepts up to 4 decimals
+	key := fmt.Sprintf("%.4f,%.4f", lat, long)
+
+	if !nwsCovers(lat, long) {
+		return gridpoint{}, fmt.Errorf("nws: %w: %s is outside the coverage area", ErrUnsupportedRegion, key)
+	}
+
+	if grid, ok := c.cachedPoint(key); ok {
+		return grid, nil
+	}
+
+	var data model.NWSPointResponse
+	status, err := c.getJSON(ctx, c.baseURL+"/points/"+key, &data)
+	if err != nil {
+		return gridpoint{}, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Not cached: gaps within the coverage area are rare, and caching them
+		// per coordinate would let arbitrary requests fill the cache
+		return gridpoint{}, fmt.Errorf("nws: %w: no forecast gridpoint for %s", ErrUnsupportedRegion, key)
+	default:
+		return gridpoint{}, fmt.Errorf("nws returned status %d", status)
+	}
+
+	grid := gridpoint{
+		office: data.Properties.GridID,
+		x:      data.Properties.GridX,
+		y:      data.Properties.GridY,
+	}
+	c.cachePoint(key, grid)
+
+	return grid, nil
+}
+
+// cachedPoint returns the cached gridpoint of a coordinate, marking it as recently used.
+func (c *NWSClient) cachedPoint(key string) (gridpoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.points[key]
+	if !ok {
+		return gridpoint{}, false
+	}
+
+	c.pointOrder.MoveToFront(e)
+	return e.Value.(pointEntry).grid, true
+}
+
+// cachePoint saves the gridpoint of a coordinate, evicting the least recently
+// used one when the cache is full.
+func (c *NWSClient) cachePoint(key string, grid gridpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.points[key]; ok {
+		c.pointOrder.MoveToFront(e)
+		return
+	}
+
+	c.points[key] = c.pointOrder.PushFront(pointEntry{key: key, grid: grid})
+
+	if c.pointOrder.Len() > c.pointLimit {
+		oldest := c.pointOrder.Back()
+		c.pointOrder.Remove(oldest)
+		delete(c.points, oldest.Value.(pointEntry).key)
+	}
+}
+
+// nwsRegions roughly bounds the areas covered by NWS forecast gridpoints, with
+// a margin for coastal waters.
+var nwsRegions = []struct {
+	minLat, maxLat, minLong, maxLong float64
+}{
+	{23, 51, -126, -65},    // contiguous United States
+	{50, 72, -180, -129},   // Alaska
+	{50, 56, 172, 180},     // western Aleutian Islands
+	{18, 23, -161, -154},   // Hawaii
+	{17, 19, -68, -64},     // Puerto Rico and the U.S. Virgin Islands
+	{13, 21, 144, 146},     // Guam and the Northern Mariana Islands
+	{-15, -13, -171, -168}, // American Samoa
+}
+
+// nwsCovers reports whether a coordinate may be covered by the NWS.
+func nwsCovers(lat, long float64) bool {
+	for _, r := range nwsRegions {
+		if lat >= r.minLat && lat <= r.maxLat && long >= r.minLong && long <= r.maxLong {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSON issues a GET request and decodes successful responses into out.
+// Non-200 responses are returned through the status code without decoding.
+func (c *NWSClient) getJSON(ctx context.Context, url string, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/geo+json")
+
+	resp, err := c.upstream.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// expandLayer spreads the interval values of a gridpoint layer over hourly slots.
+// Accumulated quantities (e.g. precipitation over PT6H) are divided evenly
+// between the hours of the interval; other values are repeated.
+func expandLayer(layer model.NWSLayer, accumulated bool) (map[time.Time]float64, error) {
+	hourly := make(map[time.Time]float64)
+
+	for _, v := range layer.Values {
+		if v.Value == nil {
+			continue
+		}
+
+		start, hours, err := parseValidTime(v.ValidTime)
+		if err != nil {
+			return nil, err
+		}
+
+		value := *v.Value
+		if accumulated {
+			value /= float64(hours)
+		}
+
+		for h := range hours {
+			hourly[start.Add(time.Duration(h)*time.Hour)] = value
+		}
+	}
+
+	return hourly, nil
+}
+
+// expandWeatherLayer maps every hour of the weather layer to a WMO weather code,
+// keeping the most severe phenomenon listed for the interval. Intervals without
+// any phenomenon are left out.
+func expandWeatherLayer(layer model.NWSWeatherLayer) (map[time.Time]int, error) {
+	hourly := make(map[time.Time]int)
+
+	for _, v := range layer.Values {
+		start, hours, err := parseValidTime(v.ValidTime)
+		if err != nil {
+			return nil, err
+		}
+
+		code, found := 0, false
+		for _, cond := range v.Value {
+			if cond.Weather == nil {
+				continue
+			}
+
+			intensity := ""
+			if cond.Intensity != nil {
+				intensity = *cond.Intensity
+			}
+
+			c, ok := nwsWeatherToWMO(*cond.Weather, intensity)
+			if ok && (!found || model.WeatherCodeRank(c) > model.WeatherCodeRank(code)) {
+				code, found = c, true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		for h := range hours {
+			hourly[start.Add(time.Duration(h)*time.Hour)] = code
+		}
+	}
+
+	return hourly, nil
+}
+
+// Map NWS weather phenomena to WMO weather codes. Phenomena without a WMO
+// counterpart, such as haze or smoke, are reported as not found.
+func nwsWeatherToWMO(weather, intensity string) (int, bool) {
+	switch weather {
+	case "thunderstorms":
+		return 95, true
+	case "freezing_rain":
+		return byIntensity(intensity, 66, 66, 67), true
+	case "freezing_drizzle":
+		return byIntensity(intensity, 56, 56, 57), true
+	case "rain_showers":
+		return byIntensity(intensity, 80, 81, 82), true
+	case "rain":
+		return byIntensity(intensity, 61, 63, 65), true
+	case "drizzle":
+		return byIntensity(intensity, 51, 53, 55), true
+	case "snow_showers":
+		return byIntensity(intensity, 85, 85, 86), true
+	case "snow", "blowing_snow":
+		return byIntensity(intensity, 71, 73, 75), true
+	case "sleet", "ice_pellets":
+		return byIntensity(intensity, 68, 69, 69), true
+	case "fog":
+		return 45, true
+	case "freezing_fog":
+		return 48, true
+	default:
+		return 0, false
+	}
+}
+
+// byIntensity picks the code matching an NWS intensity ("very_light", "light",
+// "moderate" or "heavy"). Unspecified intensities count as moderate.
+func byIntensity(intensity string, light, moderate, heavy int) int {
+	switch intensity {
+	case "very_light", "light":
+		return light
+	case "heavy":
+		return heavy
+	default:
+		return moderate
+	}
+}
+
+// skyCoverToWMO maps a sky cover percentage to the WMO cloud cover codes 0-3.
+func skyCoverToWMO(pct float64) int {
+	switch {
+	case pct < 12.5:
+		return 0
+	case pct < 37.5:
+		return 1
+	case pct < 75:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// parseValidTime parses an ISO8601 interval of the form "<start>/<duration>"
+// and returns the start truncated to the hour along with the number of hours it covers.
+func parseValidTime(validTime string) (time.Time, int, error) {
+	startStr, durStr, ok := strings.Cut(validTime, "/")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime %q", validTime)
+	}
+
+	start, err := iso8601.ParseString(startStr)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime %q: %w", validTime, err)
+	}
+
+	dur, err := parseISODuration(durStr)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime %q: %w", validTime, err)
+	}
+
+	return start.UTC().Truncate(time.Hour), max(1, int(dur/time.Hour)), nil
+}
+
+// parseISODuration parses the day and time components of an ISO8601 duration,
+// such as "PT3H" or "P1DT6H". Years, months and weeks are not used by the NWS.
+func parseISODuration(s string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var total time.Duration
+	inTime := false
+	num := ""
+
+	for _, r := range rest {
+		switch {
+		case r == 'T':
+			if inTime || num != "" {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			inTime = true
+			continue
+		case r >= '0' && r <= '9' || r == '.':
+			num += string(r)
+			continue
+		}
+
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		num = ""
+
+		var unit time.Duration
+		switch {
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("unsupported duration component %q in %q", r, s)
+		}
+
+		total += time.Duration(n * float64(unit))
+	}
+
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return total, nil
+}
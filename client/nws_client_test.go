@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// pointsServer answers /points requests with a gridpoint, or a 404 for
+// coordinates with a negative latitude, and counts the requests.
+func pointsServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		coords, ok := strings.CutPrefix(r.URL.Path, "/points/")
+		if !ok || strings.HasPrefix(coords, "-") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"properties":{"gridId":"OKX","gridX":33,"gridY":37}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestNWSResolveGridpointCache(t *testing.T) {
+	var calls atomic.Int32
+	c := NewNWSClient(NWSOptions{BaseURL: pointsServer(t, &calls).URL, PointCacheSize: 2})
+	ctx := context.Background()
+
+	resolve := func(lat, long float64) {
+		t.Helper()
+		if _, err := c.resolveGridpoint(ctx, lat, long); err != nil {
+			t.Fatalf("resolveGridpoint(%v, %v): %v", lat, long, err)
+		}
+	}
+
+	resolve(40.7128, -74.006)
+	resolve(40.7128, -74.006)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("got %d /points calls, want 1 for a cached coordinate", n)
+	}
+
+	// A third coordinate evicts the least recently used one
+	resolve(41.8781, -87.6298)
+	resolve(40.7128, -74.006)
+	resolve(34.0522, -118.2437)
+	calls.Store(0)
+
+	resolve(40.7128, -74.006)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("recently used coordinate was evicted")
+	}
+	resolve(41.8781, -87.6298)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("least recently used coordinate was not evicted")
+	}
+	if len(c.points) != 2 || c.pointOrder.Len() != 2 {
+		t.Errorf("cache holds %d entries, want 2", c.pointOrder.Len())
+	}
+}
+
+func TestNWSResolveGridpointUnsupported(t *testing.T) {
+	var calls atomic.Int32
+	c := NewNWSClient(NWSOptions{BaseURL: pointsServer(t, &calls).URL})
+	ctx := context.Background()
+
+	// Outside the coverage area, /points is not called
+	if _, err := c.resolveGridpoint(ctx, 48.8566, 2.3522); !errors.Is(err, ErrUnsupportedRegion) {
+		t.Errorf("err = %v, want ErrUnsupportedRegion", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("got %d /points calls outside the coverage area, want 0", n)
+	}
+
+	// Unsupported coordinates within it are not cached
+	for range 2 {
+		if _, err := c.resolveGridpoint(ctx, -14.2756, -170.702); !errors.Is(err, ErrUnsupportedRegion) {
+			t.Errorf("err = %v, want ErrUnsupportedRegion", err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d /points calls, want 2", n)
+	}
+	if c.pointOrder.Len() != 0 {
+		t.Errorf("unsupported coordinates were cached")
+	}
+}
@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+)
+
+// PayloadStore persists raw upstream payloads, e.g. on disk so that they survive restarts.
+type PayloadStore interface {
+	// Get returns the payload stored under key and when it was stored.
+	Get(key string) (payload []byte, storedAt time.Time, ok bool, err error)
+	// Put stores the payload under key.
+	Put(key string, payload []byte) error
+}
+
+// Defaults for OpenMeteoEndpoint
+const (
+	DefaultStoreFreshFor = 1 * time.Hour
+	DefaultStoreMaxStale = 6 * time.Hour
+)
+
+// OpenMeteoEndpoint configures how a client calls one of the Open-Meteo APIs
+// (forecast, air quality, marine). Zero values fall back to the defaults of the client.
+type OpenMeteoEndpoint struct {
+	// BaseURL is the API endpoint, e.g. a self-hosted instance or one of the
+	// commercial customer-*.open-meteo.com endpoints.
+	BaseURL string
+	// APIKey is sent as the apikey parameter for commercial endpoints.
+	APIKey string
+	// ForecastDays is the number of days of hourly data requested.
+	ForecastDays int
+
+	// Store optionally persists fetched payloads.
+	Store PayloadStore
+	// StoreFreshFor is how long a stored payload is served without calling upstream.
+	StoreFreshFor time.Duration
+	// StoreMaxStale is how old a stored payload may be to still be served
+	// when the upstream API is failing.
+	StoreMaxStale time.Duration
+	// Upstream configures retries and the circuit breaker.
+	Upstream UpstreamOptions
+}
+
+// openMeteoAPI requests hourly variables from one of the Open-Meteo APIs.
+type openMeteoAPI struct {
+	name         string
+	upstream     *upstream
+	baseURL      string
+	apiKey       string
+	forecastDays int
+	payloads     storedPayloads
+}
+
+// newOpenMeteoAPI returns the API of the named client, falling back to the
+// given base URL and number of forecast days.
+func newOpenMeteoAPI(name string, opts OpenMeteoEndpoint, baseURL string, forecastDays int) *openMeteoAPI {
+	if opts.BaseURL == "" {
+		opts.BaseURL = baseURL
+	}
+	if opts.ForecastDays <= 0 {
+		opts.ForecastDays = forecastDays
+	}
+	if opts.StoreFreshFor <= 0 {
+		opts.StoreFreshFor = DefaultStoreFreshFor
+	}
+	if opts.StoreMaxStale <= 0 {
+		opts.StoreMaxStale = DefaultStoreMaxStale
+	}
+
+	return &openMeteoAPI{
+		name:         name,
+		upstream:     newUpstream(name, opts.Upstream),
+		baseURL:      opts.BaseURL,
+		apiKey:       opts.APIKey,
+		forecastDays: opts.ForecastDays,
+		payloads: storedPayloads{
+			store:    opts.Store,
+			freshFor: opts.StoreFreshFor,
+			maxStale: opts.StoreMaxStale,
+		},
+	}
+}
+
+// fetchHourly requests the hourly variables for the coordinates, along with
+// the given query parameters, and decodes the response into T.
+//
+// When a payload store is configured, a recently stored payload is served
+// without calling upstream, and an older one is served if the upstream call fails.
+func fetchHourly[T any](ctx context.Context, api *openMeteoAPI, lat, long float64, hourly []string, params url.Values) (*T, error) {
+	u, err := url.Parse(api.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s base URL: %w", api.name, err)
+	}
+
+	q := u.Query()
+	for name, values := range params {
+		q[name] = values
+	}
+	q.Set("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("longitude", strconv.FormatFloat(long, 'f', -1, 64))
+	q.Set("hourly", strings.Join(hourly, ","))
+	q.Set("forecast_days", strconv.Itoa(api.forecastDays))
+	q.Set("timezone", "UTC")
+
+	// The store key leaves out the API key so that it is never persisted
+	u.RawQuery = q.Encode()
+	key := u.String()
+
+	if api.apiKey != "" {
+		q.Set("apikey", api.apiKey)
+		u.RawQuery = q.Encode()
+	}
+
+	return fetchStored[T](api.payloads, key, func() ([]byte, error) {
+		return api.get(ctx, u.String())
+	})
+}
+
+// get performs a request and returns the raw response body.
+func (api *openMeteoAPI) get(ctx context.Context, reqURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.upstream.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", api.name, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// storedPayloads serves upstream payloads through an optional PayloadStore.
+type storedPayloads struct {
+	store    PayloadStore
+	freshFor time.Duration
+	maxStale time.Duration
+}
+
+// fetchStored decodes the JSON payload stored under key while it is fresh.
+// Otherwise it fetches the payload and stores it once it decodes, falling back
+// to the stored payload if the fetch fails and it is not too stale.
+// Without a store every call fetches.
+func fetchStored[T any](p storedPayloads, key string, fetch func() ([]byte, error)) (*T, error) {
+	if p.store == nil {
+		payload, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return decodeJSON[T](payload)
+	}
+
+	stored, storedAt, ok, err := p.store.Get(key)
+	if err != nil {
+		logger.Log.Warn("Failed to read payload store", zap.Error(err))
+	}
+	if ok && time.Since(storedAt) < p.freshFor {
+		return decodeJSON[T](stored)
+	}
+
+	payload, err := fetch()
+	if err != nil {
+		if ok && time.Since(storedAt) < p.maxStale {
+			logger.Log.Warn("Serving stored payload after upstream failure",
+				zap.String("key", key),
+				zap.Time("stored_at", storedAt),
+				zap.Error(err),
+			)
+			return decodeJSON[T](stored)
+		}
+		return nil, err
+	}
+
+	data, err := decodeJSON[T](payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.store.Put(key, payload); err != nil {
+		logger.Log.Warn("Failed to write payload store", zap.Error(err))
+	}
+
+	return data, nil
+}
+
+func decodeJSON[T any](payload []byte) (*T, error) {
+	var data T
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// checkHourly checks that every variable of an hourly block, a struct of
+// slices decoded from an Open-Meteo payload, has a value for each time step.
+// Variables held in a map of slices, such as extra variables, are checked too.
+// Truncated payloads or unexpected variable names would otherwise index out of range.
+func checkHourly(upstream string, hourly any) error {
+	v := reflect.ValueOf(hourly)
+	steps := v.FieldByName("Time").Len()
+
+	for i := range v.NumField() {
+		f := v.Field(i)
+
+		switch {
+		case f.Kind() == reflect.Slice && f.Len() != steps:
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			return fmt.Errorf("%s: hourly %s has %d values for %d time steps", upstream, name, f.Len(), steps)
+		case f.Kind() == reflect.Map && f.Type().Elem().Kind() == reflect.Slice:
+			for iter := f.MapRange(); iter.Next(); {
+				if n := iter.Value().Len(); n != steps {
+					return fmt.Errorf("%s: hourly %s has %d values for %d time steps", upstream, iter.Key(), n, steps)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func valueOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory PayloadStore.
+type memStore struct {
+	mu       sync.Mutex
+	payloads map[string][]byte
+	storedAt map[string]time.Time
+}
+
+func newMemStore() *memStore {
+	return &memStore{payloads: make(map[string][]byte), storedAt: make(map[string]time.Time)}
+}
+
+func (s *memStore) Get(key string) ([]byte, time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload, ok := s.payloads[key]
+	return payload, s.storedAt[key], ok, nil
+}
+
+func (s *memStore) Put(key string, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.payloads[key] = payload
+	s.storedAt[key] = time.Now()
+	return nil
+}
+
+// hourlyTimes is the smallest payload fetchHourly can decode.
+type hourlyTimes struct {
+	Hourly struct {
+		Time []string `json:"time"`
+	} `json:"hourly"`
+}
+
+const hourlyTimesPayload = `{"hourly":{"time":["2026-06-01T00:00","2026-06-01T01:00"]}}`
+
+func TestFetchHourlyRequest(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(hourlyTimesPayload))
+	}))
+	defer srv.Close()
+
+	api := newOpenMeteoAPI("test", OpenMeteoEndpoint{
+		BaseURL: srv.URL + "?cell_selection=sea",
+		APIKey:  "s3cr3t-key",
+	}, "", 3)
+
+	data, err := fetchHourly[hourlyTimes](context.Background(), api, 52.52, 13.41,
+		[]string{"rain", "uv_index"}, url.Values{"models": {"icon_seamless"}})
+	if err != nil {
+		t.Fatalf("fetchHourly: %v", err)
+	}
+	if len(data.Hourly.Time) != 2 {
+		t.Errorf("got %d time steps, want 2", len(data.Hourly.Time))
+	}
+
+	want := map[string]string{
+		"latitude":       "52.52",
+		"longitude":      "13.41",
+		"hourly":         "rain,uv_index",
+		"forecast_days":  "3",
+		"timezone":       "UTC",
+		"models":         "icon_seamless",
+		"cell_selection": "sea",
+		"apikey":         "s3cr3t-key",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestFetchHourlyStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	api := newOpenMeteoAPI("test", OpenMeteoEndpoint{BaseURL: srv.URL}, "", 1)
+
+	_, err := fetchHourly[hourlyTimes](context.Background(), api, 52.52, 13.41, []string{"rain"}, nil)
+	if err == nil || err.Error() != "test returned status 400" {
+		t.Errorf("err = %v, want the upstream status", err)
+	}
+}
+
+func TestFetchHourlyPayloadStore(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(hourlyTimesPayload))
+	}))
+	defer srv.Close()
+
+	store := newMemStore()
+	newAPI := func(freshFor, maxStale time.Duration) *openMeteoAPI {
+		return newOpenMeteoAPI("test", OpenMeteoEndpoint{
+			BaseURL:       srv.URL,
+			APIKey:        "s3cr3t-key",
+			Store:         store,
+			StoreFreshFor: freshFor,
+			StoreMaxStale: maxStale,
+			Upstream:      UpstreamOptions{Retry: RetryPolicy{MaxAttempts: 1}},
+		}, "", 1)
+	}
+	fetch := func(api *openMeteoAPI) (*hourlyTimes, error) {
+		return fetchHourly[hourlyTimes](context.Background(), api, 52.52, 13.41, []string{"rain"}, nil)
+	}
+
+	// A fresh payload is served without calling upstream
+	api := newAPI(time.Hour, time.Hour)
+	for range 2 {
+		if _, err := fetch(api); err != nil {
+			t.Fatalf("fetchHourly: %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("upstream called %d times, want 1", got)
+	}
+
+	for key := range store.payloads {
+		if strings.Contains(key, "s3cr3t-key") {
+			t.Errorf("store key %q contains the API key", key)
+		}
+	}
+
+	// A stale payload is served while upstream is failing
+	failing.Store(true)
+	data, err := fetch(newAPI(time.Nanosecond, time.Hour))
+	if err != nil {
+		t.Fatalf("fetchHourly with upstream failing: %v", err)
+	}
+	if len(data.Hourly.Time) != 2 {
+		t.Errorf("got %d time steps from the stored payload, want 2", len(data.Hourly.Time))
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("upstream called %d times, want 2", got)
+	}
+
+	// A payload older than the maximum staleness is not
+	if _, err := fetch(newAPI(time.Nanosecond, time.Nanosecond)); err == nil {
+		t.Error("expected the upstream error for a too stale payload")
+	}
+}
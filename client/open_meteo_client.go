@@ -3,53 +3,154 @@ package client
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
+	"net/url"
+	"slices"
 
+	"github.com/relvacode/iso8601"
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
 	"github.com/ihgazi/EventWeatherGuard/model"
 )
 
+// Defaults for OpenMeteoOptions
+const (
+	// DefaultOpenMeteoBaseURL points at the free Open-Meteo forecast API.
+	DefaultOpenMeteoBaseURL = "https://api.open-meteo.com/v1/forecast"
+	DefaultOpenMeteoDays    = 7
+)
+
+// openMeteoHourly lists the hourly variables required to build model.HourlyForecast.
+var openMeteoHourly = []string{
+	"precipitation_probability",
+	"rain",
+	"wind_speed_10m",
+	"wind_gusts_10m",
+	"weather_code",
+	"temperature_2m",
+	"relative_humidity_2m",
+	"apparent_temperature",
+	"snowfall",
+	"snow_depth",
+	"visibility",
+	"cape",
+	"lifted_index",
+	"uv_index",
+}
+
+// OpenMeteoOptions configures an OpenMeteoClient. Zero values fall back to the defaults.
+type OpenMeteoOptions struct {
+	OpenMeteoEndpoint
+	// Model optionally selects the weather model instead of Open-Meteo's best match.
+	// Open-Meteo suffixes the variables with the model name when several are
+	// requested, so only a single model can be mapped onto the forecast.
+	Model string
+	// ExtraHourly lists hourly variables requested on top of the required ones,
+	// reported in the Extra field of the hourly forecasts.
+	ExtraHourly []string
+}
+
+// OpenMeteoClient fetches weather forecasts from the Open-Meteo forecast API.
 type OpenMeteoClient struct {
-	httpClient *http.Client
+	api    *openMeteoAPI
+	model  string
+	hourly []string
 }
 
-func NewOpenMeteoClient() *OpenMeteoClient {
+func NewOpenMeteoClient(opts OpenMeteoOptions) *OpenMeteoClient {
+	hourly := append([]string(nil), openMeteoHourly...)
+	for _, v := range opts.ExtraHourly {
+		if !slices.Contains(hourly, v) {
+			hourly = append(hourly, v)
+		}
+	}
+
 	return &OpenMeteoClient{
-		httpClient: &http.Client{Timeout: 1 * time.Minute},
+		api:    newOpenMeteoAPI("open-meteo", opts.OpenMeteoEndpoint, DefaultOpenMeteoBaseURL, DefaultOpenMeteoDays),
+		model:  opts.Model,
+		hourly: hourly,
 	}
 }
 
-// FetchWeatherData retrieves weather forecast data from the Open-Meteo API
-// for the specified latitude and longitude. It returns a parsed OpenMeteoResponse
-// or an error if the request fails.
-func (c *OpenMeteoClient) FetchWeatherData(ctx context.Context, lat, long float64) (*model.OpenMeteoResponse, error) {
-	// Open-Meteo API URL with required parameters
-	url := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&hourly=precipitation_probability,rain,wind_speed_10m,weather_code&timezone=UTC",
-		lat, long,
-	)
+// Name implements WeatherProvider.
+func (c *OpenMeteoClient) Name() string {
+	return c.api.name
+}
+
+// Breakers implements BreakerReporter.
+func (c *OpenMeteoClient) Breakers() []*CircuitBreaker {
+	return []*CircuitBreaker{c.api.upstream.breaker}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// FetchHourlyForecast implements WeatherProvider by fetching the Open-Meteo
+// forecast and normalizing it into hourly rows.
+func (c *OpenMeteoClient) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	raw, err := c.FetchWeatherData(ctx, lat, long)
 	if err != nil {
 		return nil, err
 	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	if err := checkHourly(c.api.name, raw.Hourly); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("open-meteo returned status %d", resp.StatusCode)
+	var result []model.HourlyForecast
+
+	for i, t := range raw.Hourly.Time {
+		parsed, err := iso8601.ParseString(t)
+		if err != nil {
+			logger.Log.Error("Failed to parse time", zap.String("time", t), zap.Error(err))
+			continue
+		}
+
+		result = append(result, model.HourlyForecast{
+			Time:          parsed.UTC(),
+			RainProb:      raw.Hourly.PrecipitationProbability[i],
+			Precipitation: raw.Hourly.Rain[i],
+			WindKmh:       raw.Hourly.WindSpeed10m[i],
+			GustKmh:       raw.Hourly.WindGusts10m[i],
+			WeatherCode:   raw.Hourly.WeatherCode[i],
+			Weather:       model.LookupWMO(raw.Hourly.WeatherCode[i]).Label,
+			TemperatureC:  raw.Hourly.Temperature2m[i],
+			HumidityPct:   raw.Hourly.RelativeHumidity2m[i],
+			ApparentTempC: raw.Hourly.ApparentTemperature[i],
+			SnowfallCm:    raw.Hourly.Snowfall[i],
+			// Snow depth is reported in meters
+			SnowDepthCm:  raw.Hourly.SnowDepth[i] * 100,
+			VisibilityM:  raw.Hourly.Visibility[i],
+			CapeJkg:      raw.Hourly.Cape[i],
+			LiftedIndexC: raw.Hourly.LiftedIndex[i],
+			UVIndex:      raw.Hourly.UVIndex[i],
+			Extra:        extraAt(raw.Hourly.Extra, i),
+		})
 	}
 
-	var data model.OpenMeteoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, err
+	return result, nil
+}
+
+// extraAt returns the values of the extra variables at a time step.
+func extraAt(extra map[string][]*float64, i int) map[string]float64 {
+	var values map[string]float64
+	for name, series := range extra {
+		if series[i] == nil {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]float64, len(extra))
+		}
+		values[name] = *series[i]
+	}
+	return values
+}
+
+// FetchWeatherData retrieves weather forecast data from the Open-Meteo API
+// for the specified latitude and longitude. It returns a parsed OpenMeteoResponse
+// or an error if the request fails. Payloads are served from the payload store
+// when one is configured.
+func (c *OpenMeteoClient) FetchWeatherData(ctx context.Context, lat, long float64) (*model.OpenMeteoResponse, error) {
+	params := url.Values{}
+	if c.model != "" {
+		params.Set("models", c.model)
 	}
 
-	return &data, nil
+	return fetchHourly[model.OpenMeteoResponse](ctx, c.api, lat, long, c.hourly, params)
 }
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+func TestOpenMeteoErrorsRedactAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	baseURL := srv.URL
+	srv.Close() // Connection refused
+
+	c := NewOpenMeteoClient(OpenMeteoOptions{
+		OpenMeteoEndpoint: OpenMeteoEndpoint{
+			BaseURL:  baseURL,
+			APIKey:   "s3cr3t-key",
+			Upstream: UpstreamOptions{Retry: RetryPolicy{MaxAttempts: 1}},
+		},
+	})
+
+	_, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41)
+	if err == nil {
+		t.Fatal("expected a transport error")
+	}
+	if strings.Contains(err.Error(), "s3cr3t-key") {
+		t.Errorf("error leaks the API key: %v", err)
+	}
+	if !strings.Contains(err.Error(), "apikey=REDACTED") {
+		t.Errorf("error = %v, want the URL with the API key redacted", err)
+	}
+}
+
+func TestOpenMeteoTruncatedPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every variable but rain covers the three time steps
+		w.Write([]byte(`{"hourly":{
+			"time":["2026-06-01T00:00","2026-06-01T01:00","2026-06-01T02:00"],
+			"precipitation_probability":[0,0,0],"rain":[0,0],"wind_speed_10m":[5,5,5],
+			"wind_gusts_10m":[9,9,9],"weather_code":[0,0,0],"temperature_2m":[15,15,15],
+			"relative_humidity_2m":[60,60,60],"apparent_temperature":[14,14,14],
+			"snowfall":[0,0,0],"snow_depth":[0,0,0],"visibility":[20000,20000,20000],
+			"cape":[0,0,0],"lifted_index":[3,3,3],"uv_index":[0,0,0]}}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenMeteoClient(OpenMeteoOptions{OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL}})
+
+	_, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41)
+	if err == nil || !strings.Contains(err.Error(), "hourly rain has 2 values for 3 time steps") {
+		t.Errorf("err = %v, want a truncated rain series", err)
+	}
+}
+
+func TestOpenMeteoExtraHourly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hourly := r.URL.Query().Get("hourly"); !strings.HasSuffix(hourly, ",dew_point_2m") {
+			t.Errorf("hourly = %q, want the extra variable requested", hourly)
+		}
+		w.Write([]byte(`{"hourly":{
+			"time":["2026-06-01T00:00","2026-06-01T01:00"],
+			"precipitation_probability":[0,0],"rain":[0,0],"wind_speed_10m":[5,5],
+			"wind_gusts_10m":[9,9],"weather_code":[0,0],"temperature_2m":[15,15],
+			"relative_humidity_2m":[60,60],"apparent_temperature":[14,14],
+			"snowfall":[0,0],"snow_depth":[0,0],"visibility":[20000,20000],
+			"cape":[0,0],"lifted_index":[3,3],"uv_index":[0,0],
+			"dew_point_2m":[10.5,null]}}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenMeteoClient(OpenMeteoOptions{
+		OpenMeteoEndpoint: OpenMeteoEndpoint{BaseURL: srv.URL},
+		ExtraHourly:       []string{"dew_point_2m"},
+	})
+
+	hours, err := c.FetchHourlyForecast(context.Background(), 52.52, 13.41)
+	if err != nil {
+		t.Fatalf("FetchHourlyForecast: %v", err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("got %d hours, want 2", len(hours))
+	}
+	if got, ok := hours[0].Extra["dew_point_2m"]; !ok || got != 10.5 {
+		t.Errorf("hours[0].Extra = %v, want dew_point_2m 10.5", hours[0].Extra)
+	}
+	if hours[1].Extra != nil {
+		t.Errorf("hours[1].Extra = %v, want none for a null value", hours[1].Extra)
+	}
+}
+
+func TestCheckHourlyExtra(t *testing.T) {
+	var raw model.OpenMeteoResponse
+	err := json.Unmarshal([]byte(`{"hourly":{"time":["2026-06-01T00:00","2026-06-01T01:00"],
+		"rain":[0,0],"dew_point_2m":[10.5],"weather_label":["clear","clear"]}}`), &raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := raw.Hourly.Extra["weather_label"]; ok {
+		t.Error("non-numeric variable decoded as extra")
+	}
+
+	// Leave only the extra variable short
+	h := raw.Hourly
+	h.PrecipitationProbability, h.WindSpeed10m, h.WindGusts10m = make([]int, 2), make([]float64, 2), make([]float64, 2)
+	h.WeatherCode, h.Temperature2m, h.RelativeHumidity2m = make([]int, 2), make([]float64, 2), make([]float64, 2)
+	h.ApparentTemperature, h.Snowfall, h.SnowDepth = make([]float64, 2), make([]float64, 2), make([]float64, 2)
+	h.Visibility, h.Cape, h.LiftedIndex, h.UVIndex = make([]float64, 2), make([]float64, 2), make([]float64, 2), make([]float64, 2)
+
+	err = checkHourly("open-meteo", h)
+	if err == nil || !strings.Contains(err.Error(), "hourly dew_point_2m has 1 values for 2 time steps") {
+		t.Errorf("err = %v, want a truncated dew_point_2m series", err)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// ErrUnsupportedRegion is returned by providers that do not cover the requested coordinates.
+var ErrUnsupportedRegion = errors.New("unsupported region")
+
+// WeatherProvider is implemented by every upstream weather source.
+//
+// Implementations return the hourly forecast for a location normalized into
+// model.HourlyForecast rows, ordered by time and expressed in UTC. Callers are
+// responsible for narrowing the series down to the window they need.
+type WeatherProvider interface {
+	// Name identifies the provider in logs and cache keys.
+	Name() string
+	// FetchHourlyForecast returns the full hourly forecast available for the location.
+	FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error)
+}
+
+// FallbackProvider serves forecasts from a primary provider and switches to a
+// fallback provider for coordinates the primary reports as unsupported.
+type FallbackProvider struct {
+	primary  WeatherProvider
+	fallback WeatherProvider
+}
+
+func NewFallbackProvider(primary, fallback WeatherProvider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Name implements WeatherProvider.
+func (p *FallbackProvider) Name() string {
+	return p.primary.Name() + "|" + p.fallback.Name()
+}
+
+// Breakers implements BreakerReporter.
+func (p *FallbackProvider) Breakers() []*CircuitBreaker {
+	return append(breakersOf(p.primary), breakersOf(p.fallback)...)
+}
+
+// FetchHourlyForecast implements WeatherProvider.
+func (p *FallbackProvider) FetchHourlyForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	hours, err := p.primary.FetchHourlyForecast(ctx, lat, long)
+	if errors.Is(err, ErrUnsupportedRegion) {
+		return p.fallback.FetchHourlyForecast(ctx, lat, long)
+	}
+
+	return hours, err
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Defaults for RetryPolicy
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 250 * time.Millisecond
+	DefaultRetryMaxDelay    = 10 * time.Second
+)
+
+// RetryPolicy configures retries of failed upstream calls. Zero values fall back to the defaults.
+//
+// Network errors, 5xx and 429 responses are retried with jittered exponential
+// backoff. A Retry-After header sent by the upstream takes precedence over the
+// backoff, and ends the retries when it asks to wait longer than MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the backoff ceiling before the first retry; it doubles on each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff, and is the longest Retry-After delay waited for.
+	MaxDelay time.Duration
+}
+
+// UpstreamOptions configures how a client calls its upstream API.
+type UpstreamOptions struct {
+	Retry   RetryPolicy
+	Breaker BreakerOptions
+	// Transport optionally replaces the default HTTP transport, e.g. with a FixtureTransport.
+	Transport http.RoundTripper
+}
+
+// upstream performs HTTP requests with retries, guarded by a circuit breaker.
+type upstream struct {
+	httpClient *http.Client
+	retry      RetryPolicy
+	breaker    *CircuitBreaker
+}
+
+func newUpstream(name string, opts UpstreamOptions) *upstream {
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if opts.Retry.BaseDelay <= 0 {
+		opts.Retry.BaseDelay = DefaultRetryBaseDelay
+	}
+	if opts.Retry.MaxDelay <= 0 {
+		opts.Retry.MaxDelay = DefaultRetryMaxDelay
+	}
+
+	return &upstream{
+		httpClient: &http.Client{Timeout: 1 * time.Minute, Transport: opts.Transport},
+		retry:      opts.Retry,
+		breaker:    NewCircuitBreaker(name, opts.Breaker),
+	}
+}
+
+// do sends the request, retrying retryable failures. Once the attempts are
+// exhausted, the last response is returned as is so that callers can report
+// its status. The circuit breaker records a single outcome per call. Transport
+// errors never carry the API key of the request.
+func (u *upstream) do(req *http.Request) (*http.Response, error) {
+	resp, err := u.send(req)
+	return resp, redactAPIKey(err)
+}
+
+// send performs the calls of do.
+func (u *upstream) send(req *http.Request) (*http.Response, error) {
+	if err := u.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := u.httpClient.Do(req.Clone(ctx))
+
+		if !retryable(ctx, resp, err) {
+			u.record(ctx, resp, err)
+			return resp, err
+		}
+
+		delay := u.retry.backoff(attempt)
+		after, hasAfter := retryAfter(resp)
+		if hasAfter {
+			delay = after
+		}
+
+		// Give up when out of attempts, or when the upstream asks to wait longer
+		// than allowed or than the request may last
+		deadline, hasDeadline := ctx.Deadline()
+		if attempt >= u.retry.MaxAttempts ||
+			(hasAfter && after > u.retry.MaxDelay) ||
+			(hasDeadline && time.Now().Add(delay).After(deadline)) {
+			u.record(ctx, resp, err)
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			u.breaker.Release()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// redactAPIKey masks the apikey parameter in the URL reported by a transport
+// error, which would otherwise end up in logs and error responses.
+func redactAPIKey(err error) error {
+	uerr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	u, perr := url.Parse(uerr.URL)
+	if perr != nil {
+		return &url.Error{Op: uerr.Op, URL: "(invalid URL)", Err: uerr.Err}
+	}
+
+	q := u.Query()
+	if !q.Has("apikey") {
+		return err
+	}
+	q.Set("apikey", "REDACTED")
+	u.RawQuery = q.Encode()
+
+	return &url.Error{Op: uerr.Op, URL: u.String(), Err: uerr.Err}
+}
+
+// record reports the outcome of a call to the circuit breaker. Calls abandoned
+// by the caller say nothing about the upstream's health.
+func (u *upstream) record(ctx context.Context, resp *http.Response, err error) {
+	switch {
+	case err != nil && ctx.Err() != nil:
+		u.breaker.Release()
+	case err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		u.breaker.Record(false)
+	default:
+		u.breaker.Record(true)
+	}
+}
+
+// retryable reports whether a failed attempt is worth retrying.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Transport errors are retried unless the caller gave up
+		return ctx.Err() == nil && !errors.Is(err, ErrFixtureNotFound)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns a random delay up to the exponential ceiling for the attempt ("full jitter").
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		ceiling = min(p.MaxDelay, p.BaseDelay<<shift)
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		return max(0, time.Until(at)), true
+	}
+
+	return 0, false
+}
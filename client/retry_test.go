@@ -0,0 +1,249 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// scriptedServer responds to the n-th request with the n-th status of the
+// script, repeating the last one, and counts the requests.
+func scriptedServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+// fastRetries retries without waiting noticeably.
+var fastRetries = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func get(t *testing.T, ctx context.Context, u *upstream, url string) (*http.Response, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := u.do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestUpstreamRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"success", []int{200}, 200, 1},
+		{"server errors", []int{503, 502, 200}, 200, 3},
+		{"rate limited", []int{429, 200}, 200, 2},
+		{"client error", []int{404, 200}, 404, 1},
+		{"attempts exhausted", []int{503}, 503, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := scriptedServer(t, nil, tt.statuses...)
+			u := newUpstream("test", UpstreamOptions{Retry: fastRetries})
+
+			resp, err := get(t, context.Background(), u, srv.URL)
+			if err != nil {
+				t.Fatalf("do: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpstreamRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxDelay   time.Duration
+		timeout    time.Duration
+		wantStatus int
+		wantCalls  int32
+	}{
+		// The backoff alone would wait up to an hour
+		{name: "takes precedence over the backoff", retryAfter: "0", maxDelay: time.Hour, wantStatus: 200, wantCalls: 2},
+		{name: "longer than max delay", retryAfter: "3600", maxDelay: time.Millisecond, wantStatus: 503, wantCalls: 1},
+		{name: "beyond the deadline", retryAfter: "30", maxDelay: time.Hour, timeout: time.Second, wantStatus: 503, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := scriptedServer(t, http.Header{"Retry-After": {tt.retryAfter}}, 503, 200)
+			u := newUpstream("test", UpstreamOptions{Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: tt.maxDelay}})
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			resp, err := get(t, ctx, u, srv.URL)
+			if err != nil {
+				t.Fatalf("do: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || calls.Load() != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d", resp.StatusCode, calls.Load(), tt.wantStatus, tt.wantCalls)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("returned after %v, want no long wait", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+
+		got, ok := retryAfter(resp)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// HTTP dates in the future are relative to now
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": {at}}}); !ok || got <= 0 || got > time.Minute {
+		t.Errorf("retryAfter(%q) = %v, %v; want up to a minute", at, got, ok)
+	}
+}
+
+func TestUpstreamBreakerTransitions(t *testing.T) {
+	srv, calls := scriptedServer(t, nil, 500, 500, 200)
+	u := newUpstream("test", UpstreamOptions{
+		Retry:   RetryPolicy{MaxAttempts: 1},
+		Breaker: BreakerOptions{FailureThreshold: 2, Cooldown: 50 * time.Millisecond},
+	})
+	ctx := context.Background()
+
+	state := func() BreakerState { return BreakerState(u.breaker.Status().State) }
+
+	get(t, ctx, u, srv.URL)
+	if state() != BreakerClosed {
+		t.Fatalf("state after 1 failure = %s, want closed", state())
+	}
+
+	get(t, ctx, u, srv.URL)
+	if state() != BreakerOpen {
+		t.Fatalf("state after 2 failures = %s, want open", state())
+	}
+
+	// Open: calls fail fast without reaching the upstream
+	if _, err := get(t, ctx, u, srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d calls while open, want 2", n)
+	}
+
+	// After the cooldown, a single trial call is let through and closes the breaker
+	time.Sleep(60 * time.Millisecond)
+	if err := u.breaker.Allow(); err != nil {
+		t.Fatalf("Allow after cooldown: %v", err)
+	}
+	if state() != BreakerHalfOpen {
+		t.Errorf("state after cooldown = %s, want half-open", state())
+	}
+	if err := u.breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second call during the trial: err = %v, want ErrCircuitOpen", err)
+	}
+	u.breaker.Release()
+
+	resp, err := get(t, ctx, u, srv.URL)
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("trial call: %v, %v", resp, err)
+	}
+	if state() != BreakerClosed {
+		t.Errorf("state after a successful trial = %s, want closed", state())
+	}
+}
+
+func TestUpstreamBreakerReopensOnFailedTrial(t *testing.T) {
+	srv, _ := scriptedServer(t, nil, 500)
+	u := newUpstream("test", UpstreamOptions{
+		Retry:   RetryPolicy{MaxAttempts: 1},
+		Breaker: BreakerOptions{FailureThreshold: 1, Cooldown: 20 * time.Millisecond},
+	})
+
+	get(t, context.Background(), u, srv.URL)
+	time.Sleep(30 * time.Millisecond)
+	get(t, context.Background(), u, srv.URL)
+
+	if s := u.breaker.Status(); s.State != string(BreakerOpen) {
+		t.Errorf("state after a failed trial = %s, want open", s.State)
+	}
+}
+
+func TestUpstreamCancelledDuringBackoff(t *testing.T) {
+	srv, calls := scriptedServer(t, nil, 503)
+	u := newUpstream("test", UpstreamOptions{
+		Retry:   RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour},
+		Breaker: BreakerOptions{FailureThreshold: 1},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	if _, err := get(t, ctx, u, srv.URL); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	// The caller gave up, which says nothing about the upstream
+	s := u.breaker.Status()
+	if s.State != string(BreakerClosed) || s.ConsecutiveFailures != 0 {
+		t.Errorf("breaker %s with %d failures, want closed with none", s.State, s.ConsecutiveFailures)
+	}
+	if err := u.breaker.Allow(); err != nil {
+		t.Errorf("Allow after a cancelled call: %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+// Package config loads the service configuration from environment variables.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the supported weather providers
+const (
+	ProviderOpenMeteo = "open-meteo"
+	ProviderMetNorway = "met-norway"
+	ProviderNWS       = "nws"
+)
+
+// Config holds the runtime configuration of the service.
+type Config struct {
+	// WeatherProviders lists the upstream forecast sources (WEATHER_PROVIDER,
+	// comma-separated). Several providers are merged into a consensus forecast.
+	WeatherProviders []string
+	// ConsensusMerge is the merge strategy used for consensus forecasts (CONSENSUS_MERGE).
+	ConsensusMerge string
+	// OpenMeteoBaseURL overrides the forecast endpoint (OPEN_METEO_BASE_URL).
+	OpenMeteoBaseURL string
+	// OpenMeteoAPIKey is sent to commercial Open-Meteo endpoints (OPEN_METEO_API_KEY).
+	OpenMeteoAPIKey string
+	// OpenMeteoForecastDays is the number of forecast days requested (OPEN_METEO_FORECAST_DAYS).
+	OpenMeteoForecastDays int
+	// OpenMeteoModel selects the Open-Meteo weather model (OPEN_METEO_MODELS). Only a
+	// single model is accepted, as Open-Meteo suffixes the variables of each model
+	// when several are requested.
+	OpenMeteoModel string
+	// OpenMeteoExtraHourly lists additional hourly variables (OPEN_METEO_EXTRA_HOURLY, comma-separated).
+	OpenMeteoExtraHourly []string
+	// MetNorwayBaseURL overrides the Locationforecast endpoint (MET_NORWAY_BASE_URL).
+	MetNorwayBaseURL string
+	// MetNorwayUserAgent identifies the service to MET Norway (MET_NORWAY_USER_AGENT).
+	MetNorwayUserAgent string
+	// NWSBaseURL overrides the api.weather.gov endpoint (NWS_BASE_URL).
+	NWSBaseURL string
+	// NWSUserAgent identifies the service to the NWS API (NWS_USER_AGENT).
+	NWSUserAgent string
+
+	// AirQualityEnabled adds the Open-Meteo air quality forecast to every forecast (AIR_QUALITY_ENABLED).
+	AirQualityEnabled bool
+	// AirQualityBaseURL overrides the air quality endpoint (AIR_QUALITY_BASE_URL).
+	AirQualityBaseURL string
+	// MarineBaseURL overrides the marine endpoint used for on-water events (MARINE_BASE_URL).
+	MarineBaseURL string
+
+	// CacheEnabled puts an in-process cache in front of each provider (FORECAST_CACHE_ENABLED).
+	CacheEnabled bool
+	// CacheResolution is the grid resolution in degrees used for cache keys (FORECAST_CACHE_RESOLUTION).
+	CacheResolution float64
+	// CacheModelCadence is the upstream model update interval bounding cache entries (FORECAST_CACHE_CADENCE).
+	CacheModelCadence time.Duration
+
+	// PayloadCachePath enables the on-disk Open-Meteo payload cache at the given path (PAYLOAD_CACHE_PATH).
+	PayloadCachePath string
+	// PayloadCacheMaxBytes bounds the size of the on-disk cache (PAYLOAD_CACHE_MAX_BYTES).
+	PayloadCacheMaxBytes int64
+	// PayloadCacheFreshFor is how long stored payloads are served without calling upstream (PAYLOAD_CACHE_FRESH_FOR).
+	PayloadCacheFreshFor time.Duration
+	// PayloadCacheMaxStale is how long stored payloads are kept to serve during upstream outages (PAYLOAD_CACHE_MAX_STALE).
+	PayloadCacheMaxStale time.Duration
+
+	// RetryMaxAttempts is the number of attempts per upstream call (RETRY_MAX_ATTEMPTS).
+	RetryMaxAttempts int
+	// RetryBaseDelay is the initial retry backoff (RETRY_BASE_DELAY).
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the retry backoff and the Retry-After delays waited for (RETRY_MAX_DELAY).
+	RetryMaxDelay time.Duration
+	// BreakerFailureThreshold is the number of consecutive failures opening a circuit breaker (BREAKER_FAILURE_THRESHOLD).
+	BreakerFailureThreshold int
+	// BreakerCooldown is how long an open circuit breaker fails fast (BREAKER_COOLDOWN).
+	BreakerCooldown time.Duration
+
+	// RulesConfig is the path of a YAML or JSON rule set config replacing the
+	// built-in classification rules (RULES_CONFIG).
+	RulesConfig string
+	// RulesReloadInterval is how often the rule set config is checked for changes (RULES_RELOAD_INTERVAL).
+	RulesReloadInterval time.Duration
+
+	// AdminTokens enables the admin API, mapping each bearer token to the name
+	// of its admin (ADMIN_TOKENS, comma-separated name:token pairs).
+	AdminTokens map[string]string
+	// AdminDBPath is the path of the database holding the rule set revisions (ADMIN_DB_PATH).
+	AdminDBPath string
+
+	// FixtureMode records upstream responses to fixtures or replays them (FIXTURE_MODE: record, replay).
+	FixtureMode string
+	// FixtureDir is the directory holding the fixture files (FIXTURE_DIR).
+	FixtureDir string
+}
+
+// Load reads the configuration from the environment, applying defaults
+// for unset variables.
+func Load() (Config, error) {
+	cfg := Config{
+		ConsensusMerge:       os.Getenv("CONSENSUS_MERGE"),
+		OpenMeteoBaseURL:     os.Getenv("OPEN_METEO_BASE_URL"),
+		OpenMeteoAPIKey:      os.Getenv("OPEN_METEO_API_KEY"),
+		OpenMeteoExtraHourly: getList("OPEN_METEO_EXTRA_HOURLY"),
+		MetNorwayBaseURL:     os.Getenv("MET_NORWAY_BASE_URL"),
+		MetNorwayUserAgent:   os.Getenv("MET_NORWAY_USER_AGENT"),
+		NWSBaseURL:           os.Getenv("NWS_BASE_URL"),
+		NWSUserAgent:         os.Getenv("NWS_USER_AGENT"),
+		AirQualityBaseURL:    os.Getenv("AIR_QUALITY_BASE_URL"),
+		MarineBaseURL:        os.Getenv("MARINE_BASE_URL"),
+		PayloadCachePath:     os.Getenv("PAYLOAD_CACHE_PATH"),
+		RulesConfig:          os.Getenv("RULES_CONFIG"),
+		AdminDBPath:          getEnv("ADMIN_DB_PATH", "rulesets.db"),
+		FixtureMode:          os.Getenv("FIXTURE_MODE"),
+		FixtureDir:           getEnv("FIXTURE_DIR", "testdata/fixtures"),
+	}
+
+	var err error
+	if cfg.AirQualityEnabled, err = getBool("AIR_QUALITY_ENABLED", true); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheEnabled, err = getBool("FORECAST_CACHE_ENABLED", true); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheResolution, err = getFloat("FORECAST_CACHE_RESOLUTION", 0.1); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheModelCadence, err = getDuration("FORECAST_CACHE_CADENCE", time.Hour); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.PayloadCacheMaxBytes, err = getInt("PAYLOAD_CACHE_MAX_BYTES", 64<<20); err != nil {
+		return Config{}, err
+	}
+	if cfg.PayloadCacheFreshFor, err = getDuration("PAYLOAD_CACHE_FRESH_FOR", time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.PayloadCacheMaxStale, err = getDuration("PAYLOAD_CACHE_MAX_STALE", 6*time.Hour); err != nil {
+		return Config{}, err
+	}
+
+	var n int64
+	if n, err = getInt("OPEN_METEO_FORECAST_DAYS", 7); err != nil {
+		return Config{}, err
+	}
+	cfg.OpenMeteoForecastDays = int(n)
+	if n, err = getInt("RETRY_MAX_ATTEMPTS", 3); err != nil {
+		return Config{}, err
+	}
+	cfg.RetryMaxAttempts = int(n)
+	if cfg.RetryBaseDelay, err = getDuration("RETRY_BASE_DELAY", 250*time.Millisecond); err != nil {
+		return Config{}, err
+	}
+	if cfg.RetryMaxDelay, err = getDuration("RETRY_MAX_DELAY", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if n, err = getInt("BREAKER_FAILURE_THRESHOLD", 5); err != nil {
+		return Config{}, err
+	}
+	cfg.BreakerFailureThreshold = int(n)
+	if cfg.BreakerCooldown, err = getDuration("BREAKER_COOLDOWN", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.RulesReloadInterval, err = getDuration("RULES_RELOAD_INTERVAL", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+
+	switch models := getList("OPEN_METEO_MODELS"); len(models) {
+	case 0:
+	case 1:
+		cfg.OpenMeteoModel = models[0]
+	default:
+		return Config{}, fmt.Errorf("OPEN_METEO_MODELS must name a single model, got %d", len(models))
+	}
+
+	switch cfg.FixtureMode {
+	case "", "record", "replay":
+	default:
+		return Config{}, fmt.Errorf("unsupported FIXTURE_MODE %q", cfg.FixtureMode)
+	}
+
+	for i, pair := range getList("ADMIN_TOKENS") {
+		// Tokens are secrets, so entries are only reported by position
+		name, token, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || token == "" {
+			return Config{}, fmt.Errorf("invalid ADMIN_TOKENS entry %d: must be name:token", i+1)
+		}
+		if cfg.AdminTokens == nil {
+			cfg.AdminTokens = make(map[string]string)
+		}
+		cfg.AdminTokens[token] = name
+	}
+
+	for _, name := range strings.Split(getEnv("WEATHER_PROVIDER", ProviderOpenMeteo), ",") {
+		name = strings.TrimSpace(name)
+
+		switch name {
+		case ProviderOpenMeteo, ProviderMetNorway, ProviderNWS:
+			cfg.WeatherProviders = append(cfg.WeatherProviders, name)
+		default:
+			return Config{}, fmt.Errorf("unsupported WEATHER_PROVIDER %q", name)
+		}
+	}
+
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getList splits a comma-separated variable, dropping empty entries.
+func getList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func getBool(key string, fallback bool) (bool, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return b, nil
+}
+
+func getInt(key string, fallback int64) (int64, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive integer", key, v)
+	}
+	return n, nil
+}
+
+func getFloat(key string, fallback float64) (float64, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive number", key, v)
+	}
+	return f, nil
+}
+
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration", key, v)
+	}
+	return d, nil
+}
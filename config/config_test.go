@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestLoadOpenMeteoModels(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"icon_seamless", "icon_seamless", false},
+		{" gfs_seamless ", "gfs_seamless", false},
+		{"icon_seamless,gfs_seamless", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("OPEN_METEO_MODELS", tt.value)
+
+		cfg, err := Load()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("OPEN_METEO_MODELS=%q: err = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if cfg.OpenMeteoModel != tt.want {
+			t.Errorf("OPEN_METEO_MODELS=%q: model = %q, want %q", tt.value, cfg.OpenMeteoModel, tt.want)
+		}
+	}
+}
@@ -15,6 +15,412 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/profiles": {
+            "get": {
+                "description": "Returns the event-type profiles of the rule set in service, with every threshold and weight.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List event-type profiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/classification.ProfileConfig"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/profiles/{name}": {
+            "put": {
+                "description": "Sets the profile of an event type in the rule set in service, storing the result as a new revision put into service right away.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or update an event-type profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event type",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Profile",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/classification.ProfileConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Removes the profile of an event type from the rule set in service, storing the result as a new revision put into service right away.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete an event-type profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event type",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetRevision"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/rulesets": {
+            "get": {
+                "description": "Returns the stored rule set revisions, the version in service and the log of activations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List rule set revisions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Validates and stores a rule set config as a new revision, with a version assigned by the service. The revision is put into service when activate is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a rule set revision",
+                "parameters": [
+                    {
+                        "description": "Rule set revision",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRuleSetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/rulesets/{version}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a rule set revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule set version, e.g. r3",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetRevision"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
+        "/admin/rulesets/{version}/activate": {
+            "post": {
+                "description": "Puts a stored rule set revision into service, rolling back or forward to it. The activation is logged with its author.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Activate a rule set revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule set version, e.g. r3",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleSetActivation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ]
+            }
+        },
         "/event-forecast": {
             "post": {
                 "description": "Returns weather risk assessment for a given event location and time window. Optionally fetches alternate time windows, in case current window is Unsafe or Risky.",
@@ -75,9 +481,113 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/upstream-status": {
+            "get": {
+                "description": "Returns the circuit breaker state of every upstream weather provider. Open breakers fail requests fast until their retry time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upstream"
+                ],
+                "summary": "Get upstream provider status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpstreamStatusResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "classification.ProfileConfig": {
+            "type": "object",
+            "properties": {
+                "exclude": {
+                    "description": "Exclude lists the IDs of the rules disabled for the event type. Built-in\nrules may be listed even when the rule set leaves them out.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rules": {
+                    "description": "Rules lists the IDs of the rules enabled for the event type. Every rule\nof the rule set is enabled when left out.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "thresholds": {
+                    "type": "object"
+                },
+                "weights": {
+                    "type": "object"
+                }
+            }
+        },
+        "model.AirQuality": {
+            "type": "object",
+            "properties": {
+                "european_aqi": {
+                    "type": "integer"
+                },
+                "ozone": {
+                    "type": "number"
+                },
+                "pm10": {
+                    "type": "number"
+                },
+                "pm2_5": {
+                    "type": "number"
+                },
+                "us_aqi": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "retry_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateRuleSetRequest": {
+            "type": "object",
+            "required": [
+                "config"
+            ],
+            "properties": {
+                "activate": {
+                    "description": "Activate puts the new revision into service right away.",
+                    "type": "boolean"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "config": {
+                    "description": "Config is a rule set config. Its version is assigned by the service.",
+                    "type": "object"
+                }
+            }
+        },
         "model.EventForecastRequest": {
             "type": "object",
             "required": [
@@ -90,6 +600,10 @@ const docTemplate = `{
                 "end_time": {
                     "type": "string"
                 },
+                "event_type": {
+                    "description": "EventType selects the risk profile of the event, e.g. \"marathon\" or \"wedding\".",
+                    "type": "string"
+                },
                 "list_alternates": {
                     "type": "boolean"
                 },
@@ -101,12 +615,37 @@ const docTemplate = `{
                 },
                 "start_time": {
                     "type": "string"
+                },
+                "thresholds": {
+                    "description": "Thresholds and Weights override individual severity thresholds and weights\nof the profile, e.g. {\"risky_wind_kmh\": 20}.",
+                    "type": "object"
+                },
+                "venue_type": {
+                    "description": "VenueType enables the marine forecast and rules for on-water events (\"water\").",
+                    "enum": [
+                        "land",
+                        "water"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.VenueType"
+                        }
+                    ]
+                },
+                "weights": {
+                    "type": "object"
                 }
             }
         },
         "model.EventForecastResponse": {
             "type": "object",
             "properties": {
+                "advisories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "alternate_timings": {
                     "type": "array",
                     "items": {
@@ -116,12 +655,21 @@ const docTemplate = `{
                 "classification": {
                     "type": "string"
                 },
+                "confidence": {
+                    "type": "string"
+                },
+                "config_version": {
+                    "type": "string"
+                },
                 "forecast_window": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/model.HourlyForecast"
                     }
                 },
+                "profile": {
+                    "type": "string"
+                },
                 "reasons": {
                     "type": "array",
                     "items": {
@@ -153,18 +701,84 @@ const docTemplate = `{
         "model.HourlyForecast": {
             "type": "object",
             "properties": {
+                "air_quality": {
+                    "description": "AirQuality is only set when an air quality forecast is available for the hour.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.AirQuality"
+                        }
+                    ]
+                },
+                "apparent_temp_c": {
+                    "type": "number"
+                },
+                "cape_jkg": {
+                    "description": "Atmospheric instability: convective available potential energy (J/kg) and lifted index (°C)",
+                    "type": "number"
+                },
+                "extra": {
+                    "description": "Extra holds the additional variables requested from the provider, keyed\nby variable name, for the hours with a value.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "gust_kmh": {
+                    "type": "number"
+                },
+                "humidity_pct": {
+                    "type": "number"
+                },
+                "lifted_index": {
+                    "type": "number"
+                },
+                "marine": {
+                    "description": "Marine is only set for on-water events, when a marine forecast is available for the hour.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Marine"
+                        }
+                    ]
+                },
                 "precip_mm": {
                     "type": "number"
                 },
+                "provider_spread": {
+                    "description": "Spread is only set when several providers reported the hour.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ProviderSpread"
+                        }
+                    ]
+                },
                 "rain_prob": {
                     "type": "integer"
                 },
+                "snow_depth_cm": {
+                    "type": "number"
+                },
+                "snowfall_cm": {
+                    "type": "number"
+                },
+                "temp_c": {
+                    "type": "number"
+                },
                 "time": {
                     "type": "string"
                 },
+                "uv_index": {
+                    "type": "number"
+                },
+                "visibility_m": {
+                    "description": "VisibilityM is zero when the provider does not report visibility.",
+                    "type": "number"
+                },
                 "weather": {
                     "type": "string"
                 },
+                "weather_code": {
+                    "type": "integer"
+                },
                 "wind_kmh": {
                     "type": "number"
                 }
@@ -184,6 +798,129 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "model.Marine": {
+            "type": "object",
+            "properties": {
+                "swell_direction_deg": {
+                    "type": "number"
+                },
+                "swell_height_m": {
+                    "type": "number"
+                },
+                "swell_period_s": {
+                    "type": "number"
+                },
+                "wave_height_m": {
+                    "type": "number"
+                },
+                "wave_period_s": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.ProviderSpread": {
+            "type": "object",
+            "properties": {
+                "precip_mm": {
+                    "type": "number"
+                },
+                "providers": {
+                    "type": "integer"
+                },
+                "rain_prob": {
+                    "type": "integer"
+                },
+                "wind_kmh": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.RuleSetActivation": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "author": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RuleSetListResponse": {
+            "type": "object",
+            "properties": {
+                "activations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RuleSetActivation"
+                    }
+                },
+                "active": {
+                    "description": "Active is the version of the rule set in service.",
+                    "type": "string"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RuleSetRevision"
+                    }
+                }
+            }
+        },
+        "model.RuleSetRevision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "config": {
+                    "description": "Config is the rule set config, left out of listings.",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpstreamStatusResponse": {
+            "type": "object",
+            "properties": {
+                "breakers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BreakerStatus"
+                    }
+                }
+            }
+        },
+        "model.VenueType": {
+            "type": "string",
+            "enum": [
+                "land",
+                "water"
+            ],
+            "x-enum-varnames": [
+                "VenueLand",
+                "VenueWater"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin token, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,241 @@
+// This file defines the authenticated admin handlers managing rule sets and profiles.
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+	"github.com/ihgazi/EventWeatherGuard/store"
+)
+
+// adminKey is the context key of the name of the authenticated admin.
+const adminKey = "admin"
+
+// AdminAuth returns a middleware authenticating admins by a bearer token. The
+// tokens map each token to the name of its admin, recorded as the author of changes.
+func AdminAuth(tokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		name := ""
+		for t, n := range tokens {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				name = n
+			}
+		}
+
+		if !ok || name == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid admin token."})
+			return
+		}
+
+		c.Set(adminKey, name)
+		c.Next()
+	}
+}
+
+// ListRuleSetsHandler returns a handler listing the stored rule set revisions.
+//
+// @Summary      List rule set revisions
+// @Description  Returns the stored rule set revisions, the version in service and the log of activations.
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Success      200      {object}  model.RuleSetListResponse
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/rulesets [get]
+func ListRuleSetsHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := admin.Revisions()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+// GetRuleSetHandler returns a handler returning a rule set revision with its config.
+//
+// @Summary      Get a rule set revision
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Param        version  path      string  true  "Rule set version, e.g. r3"
+// @Success      200      {object}  model.RuleSetRevision
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/rulesets/{version} [get]
+func GetRuleSetHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rev, err := admin.Revision(c.Param("version"))
+		if err != nil {
+			adminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, rev)
+	}
+}
+
+// CreateRuleSetHandler returns a handler storing a new rule set revision.
+//
+// @Summary      Create a rule set revision
+// @Description  Validates and stores a rule set config as a new revision, with a version assigned by the service. The revision is put into service when activate is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        request  body      model.CreateRuleSetRequest  true  "Rule set revision"
+// @Success      201      {object}  model.RuleSetRevision
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/rulesets [post]
+func CreateRuleSetHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req model.CreateRuleSetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		cfg, err := cls.DecodeRuleSetConfig(req.Config, "json")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rev, err := admin.Create(c.GetString(adminKey), req.Comment, cfg, req.Activate)
+		if err != nil {
+			adminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, rev)
+	}
+}
+
+// ActivateRuleSetHandler returns a handler putting a rule set revision into service.
+//
+// @Summary      Activate a rule set revision
+// @Description  Puts a stored rule set revision into service, rolling back or forward to it. The activation is logged with its author.
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Param        version  path      string  true  "Rule set version, e.g. r3"
+// @Success      200      {object}  model.RuleSetActivation
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/rulesets/{version}/activate [post]
+func ActivateRuleSetHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		activation, err := admin.Activate(c.Param("version"), c.GetString(adminKey))
+		if err != nil {
+			adminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, activation)
+	}
+}
+
+// ListProfilesHandler returns a handler listing the event-type profiles in service.
+//
+// @Summary      List event-type profiles
+// @Description  Returns the event-type profiles of the rule set in service, with every threshold and weight.
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Success      200      {object}  map[string]classification.ProfileConfig
+// @Failure      401      {object}  map[string]string
+// @Router       /admin/profiles [get]
+func ListProfilesHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, admin.Profiles())
+	}
+}
+
+// PutProfileHandler returns a handler creating or replacing an event-type profile.
+//
+// @Summary      Create or update an event-type profile
+// @Description  Sets the profile of an event type in the rule set in service, storing the result as a new revision put into service right away.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        name     path      string                        true  "Event type"
+// @Param        request  body      classification.ProfileConfig  true  "Profile"
+// @Success      200      {object}  model.RuleSetRevision
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/profiles/{name} [put]
+func PutProfileHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var profile cls.ProfileConfig
+		if err := c.ShouldBindJSON(&profile); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rev, err := admin.PutProfile(c.GetString(adminKey), c.Param("name"), profile)
+		if err != nil {
+			adminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, rev)
+	}
+}
+
+// DeleteProfileHandler returns a handler removing an event-type profile.
+//
+// @Summary      Delete an event-type profile
+// @Description  Removes the profile of an event type from the rule set in service, storing the result as a new revision put into service right away.
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Param        name     path      string  true  "Event type"
+// @Success      200      {object}  model.RuleSetRevision
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/profiles/{name} [delete]
+func DeleteProfileHandler(admin *service.RuleAdmin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rev, err := admin.DeleteProfile(c.GetString(adminKey), c.Param("name"))
+		if err != nil {
+			adminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, rev)
+	}
+}
+
+// adminError responds with the status matching an admin error.
+func adminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, service.ErrInvalidRuleSet):
+		status = http.StatusBadRequest
+	case errors.Is(err, store.ErrRevisionNotFound), errors.Is(err, service.ErrProfileNotFound):
+		status = http.StatusNotFound
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service"
+	"github.com/ihgazi/EventWeatherGuard/store"
+)
+
+const adminToken = "s3cr3t-token"
+
+// adminRouter serves the admin API as main does, over a temporary rule set
+// store. The token authenticates alice.
+func adminRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	revisions, err := store.OpenRuleSetStore(filepath.Join(t.TempDir(), "rulesets.db"))
+	if err != nil {
+		t.Fatalf("OpenRuleSetStore: %v", err)
+	}
+	t.Cleanup(func() { revisions.Close() })
+
+	admin := service.NewRuleAdmin(revisions, service.NewRuleSource())
+
+	router := gin.New()
+	api := router.Group("/admin", AdminAuth(map[string]string{adminToken: "alice"}))
+	api.GET("/rulesets", ListRuleSetsHandler(admin))
+	api.POST("/rulesets", CreateRuleSetHandler(admin))
+	api.GET("/rulesets/:version", GetRuleSetHandler(admin))
+	api.POST("/rulesets/:version/activate", ActivateRuleSetHandler(admin))
+	api.GET("/profiles", ListProfilesHandler(admin))
+	api.PUT("/profiles/:name", PutProfileHandler(admin))
+	api.DELETE("/profiles/:name", DeleteProfileHandler(admin))
+
+	return router
+}
+
+func adminRequest(router *gin.Engine, method, path, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuth(t *testing.T) {
+	router := adminRouter(t)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "empty bearer", authorization: "Bearer ", want: http.StatusUnauthorized},
+		{name: "other scheme", authorization: "Basic " + adminToken, want: http.StatusUnauthorized},
+		{name: "no scheme", authorization: adminToken, want: http.StatusUnauthorized},
+		{name: "unknown token", authorization: "Bearer wrong-token", want: http.StatusUnauthorized},
+		{name: "token prefix", authorization: "Bearer " + adminToken[:4], want: http.StatusUnauthorized},
+		{name: "valid", authorization: "Bearer " + adminToken, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := adminRequest(router, http.MethodGet, "/admin/rulesets", tt.authorization, "")
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestAdminAuthRecordsAuthor(t *testing.T) {
+	router := adminRouter(t)
+
+	w := adminRequest(router, http.MethodPost, "/admin/rulesets", "Bearer "+adminToken, `{"config":{}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body)
+	}
+
+	var rev model.RuleSetRevision
+	if err := json.Unmarshal(w.Body.Bytes(), &rev); err != nil {
+		t.Fatal(err)
+	}
+	if rev.Author != "alice" {
+		t.Errorf("author = %q, want the admin of the token", rev.Author)
+	}
+}
+
+func TestAdminStatusCodes(t *testing.T) {
+	router := adminRouter(t)
+
+	// Steps run in order against the same store
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"create", http.MethodPost, "/admin/rulesets", `{"comment":"Initial","config":{"version":"ignored"}}`, http.StatusCreated},
+		{"create malformed", http.MethodPost, "/admin/rulesets", `{"config":`, http.StatusBadRequest},
+		{"create without config", http.MethodPost, "/admin/rulesets", `{"comment":"Empty"}`, http.StatusBadRequest},
+		{"create unknown field", http.MethodPost, "/admin/rulesets", `{"config":{"rulez":[]}}`, http.StatusBadRequest},
+		{"create unknown rule", http.MethodPost, "/admin/rulesets", `{"config":{"rules":[{"id":"RISKY_HAIL"}]}}`, http.StatusBadRequest},
+		{"get", http.MethodGet, "/admin/rulesets/r1", "", http.StatusOK},
+		{"get unknown", http.MethodGet, "/admin/rulesets/r9", "", http.StatusNotFound},
+		{"activate", http.MethodPost, "/admin/rulesets/r1/activate", "", http.StatusOK},
+		{"activate unknown", http.MethodPost, "/admin/rulesets/r9/activate", "", http.StatusNotFound},
+		{"put profile", http.MethodPut, "/admin/profiles/regatta", `{"thresholds":{"risky_wind_kmh":20},"rules":[]}`, http.StatusOK},
+		{"put invalid profile", http.MethodPut, "/admin/profiles/regatta", `{"thresholds":{"risky_wind_kmh":50}}`, http.StatusBadRequest},
+		{"put malformed profile", http.MethodPut, "/admin/profiles/regatta", `{"thresholds":`, http.StatusBadRequest},
+		{"list profiles", http.MethodGet, "/admin/profiles", "", http.StatusOK},
+		{"delete profile", http.MethodDelete, "/admin/profiles/regatta", "", http.StatusOK},
+		{"delete unknown profile", http.MethodDelete, "/admin/profiles/regatta", "", http.StatusNotFound},
+		{"list", http.MethodGet, "/admin/rulesets", "", http.StatusOK},
+	}
+
+	for _, step := range steps {
+		w := adminRequest(router, step.method, step.path, "Bearer "+adminToken, step.body)
+		if w.Code != step.want {
+			t.Errorf("%s: status = %d, want %d: %s", step.name, w.Code, step.want, w.Body)
+		}
+	}
+
+	// The profile edits were stored as revisions and put into service
+	w := adminRequest(router, http.MethodGet, "/admin/rulesets", "Bearer "+adminToken, "")
+
+	var list model.RuleSetListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Active != "r3" || len(list.Revisions) != 3 || len(list.Activations) != 3 {
+		t.Errorf("list = %+v, want r1 and the two profile revisions, all activated", list)
+	}
+}
@@ -5,19 +5,23 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
-	"github.com/ihgazi/EventWeatherGuard/client"
 	"github.com/ihgazi/EventWeatherGuard/logger"
 	"github.com/ihgazi/EventWeatherGuard/model"
 	"github.com/ihgazi/EventWeatherGuard/service"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
 )
 
-// EventForecastHandler handles POST requests for event weather forecasts.
+// EventForecastHandler returns a handler for POST requests for event weather forecasts,
+// backed by the given weather service and classified with the active rule set of the given source.
+// Event times are checked against the current time given by now, e.g. time.Now.
 //
 // @Summary      Get event weather forecast and risk classification
 // @Description  Returns weather risk assessment for a given event location and time window. Optionally fetches alternate time windows, in case current window is Unsafe or Risky.
@@ -30,7 +34,18 @@ import (
 // @Failure 	 404 	  {object}  map[string]string
 // @Failure      500      {object}  map[string]string
 // @Router       /event-forecast [post]
-func EventForecastHandler(c *gin.Context) {
+func EventForecastHandler(
+	weatherSvc *service.WeatherService,
+	rules *service.RuleSource,
+	now func() time.Time,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The whole request is classified with the same rules, even if they are reloaded meanwhile
+		eventForecast(c, weatherSvc, rules.Current(), now().UTC())
+	}
+}
+
+func eventForecast(c *gin.Context, weatherSvc *service.WeatherService, ruleSet *cls.RuleSet, now time.Time) {
 	var req model.EventForecastRequest
 
 	// Bind and validate JSON request body
@@ -40,7 +55,7 @@ func EventForecastHandler(c *gin.Context) {
 	}
 
 	// Validate time window of event
-	if !validateEventTime(req.StartTime.Time, req.EndTime.Time) {
+	if !validateEventTime(req.StartTime.Time, req.EndTime.Time, now) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid event timings: Event duration must be positive and lie within next 6 days.",
 		})
@@ -55,10 +70,23 @@ func EventForecastHandler(c *gin.Context) {
 		return
 	}
 
-	// Initialize weather service with Open-Meteo client
-	weatherSvc := service.NewWeatherService(
-		client.NewOpenMeteoClient(),
-	)
+	// Select the risk profile of the event type
+	profile, ok := ruleSet.Lookup(req.EventType)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Unknown event type %q: must be one of %s.", req.EventType, strings.Join(ruleSet.EventTypes(), ", ")),
+		})
+		return
+	}
+
+	// Apply the thresholds and weights requested for this event
+	if len(req.Thresholds) > 0 || len(req.Weights) > 0 {
+		var err error
+		if profile, err = profile.WithOverrides(req.Thresholds, req.Weights); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 1*time.Minute)
 	defer cancel()
@@ -70,6 +98,7 @@ func EventForecastHandler(c *gin.Context) {
 		req.Location.Longitude,
 		req.StartTime.UTC(),
 		req.EndTime.UTC(),
+		req.VenueType,
 	)
 
 	if err != nil {
@@ -83,26 +112,30 @@ func EventForecastHandler(c *gin.Context) {
 		return
 	}
 
-	result := service.ClassifyEvent(forecast)
+	criteria := cls.Criteria{Rules: ruleSet.Rules, Profile: profile, Venue: req.VenueType}
+	result := service.ClassifyEvent(forecast, criteria)
 
 	response := model.EventForecastResponse{
 		Classification: string(result.Classification),
 		Summary:        result.Summary,
 		Reasons:        result.Reason,
+		Advisories:     result.Advisories,
 		Severity:       result.Severity,
+		Confidence:     string(result.Confidence),
+		Profile:        profile.Name,
+		ConfigVersion:  ruleSet.Version,
 		ForecastWindow: forecast,
 	}
 
-	if req.ListAlters && response.Classification != "Safe" {
-		response.AlternateWindows = alternateWindows(ctx, req, weatherSvc)
+	// Advisories alone do not call for alternate timings
+	if req.ListAlters && (result.Classification == cls.Risky || result.Classification == cls.Unsafe) {
+		response.AlternateWindows = alternateWindows(ctx, req, criteria, weatherSvc, now)
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-func validateEventTime(start, end time.Time) bool {
-	now := time.Now().UTC()
-
+func validateEventTime(start, end, now time.Time) bool {
 	if !start.After(now) || !end.After(start) {
 		return false
 	}
@@ -126,18 +159,20 @@ func validateLocation(l model.Location) error {
 
 // alternateWindows suggests alternate time windows for an event with optimal weather conditions.
 //
-// Given an event request, this function analyzes the weather forecast for the next 24 hours
+// Given an event request, this function analyzes the weather forecast for the 24 hours from now
 // and returns up to three alternate time slots that best match the event's duration and weather suitability.
 func alternateWindows(
 	ctx context.Context,
 	req model.EventForecastRequest,
+	criteria cls.Criteria,
 	weatherSvc *service.WeatherService,
+	now time.Time,
 ) []model.EventWindow {
 	eventHours := int(req.EndTime.Sub(req.StartTime.Time).Hours())
 
-	winStart := time.Now().UTC()
+	winStart := now
 	winEnd := winStart.Add(24 * time.Hour)
-	oneDayForecast, err := weatherSvc.GetEventForecast(ctx, req.Location.Latitude, req.Location.Longitude, winStart, winEnd)
+	oneDayForecast, err := weatherSvc.GetEventForecast(ctx, req.Location.Latitude, req.Location.Longitude, winStart, winEnd, req.VenueType)
 	if err != nil {
 		logger.Log.Error("Failed to fetch alternate times: ", zap.Error(err))
 	}
@@ -146,6 +181,7 @@ func alternateWindows(
 		oneDayForecast,
 		eventHours,
 		3, // Fetch best 3 possible alternate timings
+		criteria,
 	)
 
 	return alternates
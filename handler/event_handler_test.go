@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/client"
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// replayRouter serves the event forecast endpoint from the Open-Meteo fixtures,
+// at the time they were recorded. It returns the day of the recording.
+//
+// The fixtures are synthetic rather than recorded from the live API, so that
+// they cover the cases tested. They hold a 7-day forecast for Berlin
+// (52.52, 13.41): calm weather, except for a thunderstorm from 14:00 to 18:00
+// on the next day, and gusts of 60 km/h over a calm 15 km/h average from 12:00
+// to 15:00 the day after.
+func replayRouter(t *testing.T) (*gin.Engine, time.Time) {
+	t.Helper()
+
+	fixtures, err := client.NewFixtureTransport(client.FixtureReplay, "testdata/fixtures", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordedAt, err := fixtures.RecordedAt()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weatherSvc := service.NewWeatherService(client.NewOpenMeteoClient(client.OpenMeteoOptions{
+		OpenMeteoEndpoint: client.OpenMeteoEndpoint{
+			Upstream: client.UpstreamOptions{Transport: fixtures},
+		},
+	}))
+
+	router := gin.New()
+	router.POST("/event-forecast", EventForecastHandler(weatherSvc, service.NewRuleSource(), func() time.Time {
+		return recordedAt
+	}))
+
+	return router, recordedAt.Truncate(24 * time.Hour)
+}
+
+func postEvent(t *testing.T, router *gin.Engine, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/event-forecast", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func eventRequest(lat, long float64, start, end time.Time, extra string) string {
+	return fmt.Sprintf(`{"name":"Open-air concert","location":{"latitude":%v,"longitude":%v},"start_time":%q,"end_time":%q%s}`,
+		lat, long, start.Format(time.RFC3339), end.Format(time.RFC3339), extra)
+}
+
+func TestEventForecastReplay(t *testing.T) {
+	router, day := replayRouter(t)
+	next, after := day.Add(24*time.Hour), day.Add(48*time.Hour)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       string
+		wantHours  int
+	}{
+		{"calm morning", next.Add(9 * time.Hour), next.Add(12 * time.Hour), "Safe", 3},
+		{"thunderstorm", next.Add(13 * time.Hour), next.Add(17 * time.Hour), "Unsafe", 4},
+		{"gusts", after.Add(12 * time.Hour), after.Add(15 * time.Hour), "Unsafe", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postEvent(t, router, eventRequest(52.52, 13.41, tt.start, tt.end, ""))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+
+			var resp model.EventForecastResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Classification != tt.want {
+				t.Errorf("classification = %s, want %s (reasons %v)", resp.Classification, tt.want, resp.Reasons)
+			}
+			if len(resp.ForecastWindow) != tt.wantHours {
+				t.Errorf("got %d forecast hours, want %d", len(resp.ForecastWindow), tt.wantHours)
+			}
+			if resp.ConfigVersion != "builtin" || resp.Profile != "default" {
+				t.Errorf("config_version %q, profile %q; want builtin, default", resp.ConfigVersion, resp.Profile)
+			}
+		})
+	}
+}
+
+func TestEventForecastReplayAlternates(t *testing.T) {
+	router, day := replayRouter(t)
+	storm := day.Add(24*time.Hour + 14*time.Hour)
+
+	w := postEvent(t, router, eventRequest(52.52, 13.41, storm, storm.Add(2*time.Hour), `,"list_alternates":true`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	var resp model.EventForecastResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.AlternateWindows) == 0 {
+		t.Fatal("no alternate timings")
+	}
+	for _, alt := range resp.AlternateWindows {
+		// Within the 24 hours following the recording, and clear of the storm
+		if alt.StartTime.Before(day) || alt.EndTime.After(storm) {
+			t.Errorf("alternate timing %v-%v outside the calm hours", alt.StartTime, alt.EndTime)
+		}
+	}
+}
+
+func TestEventForecastReplayErrors(t *testing.T) {
+	router, day := replayRouter(t)
+	next := day.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		body     string
+		want     int
+		wantBody string
+	}{
+		{
+			name: "past event",
+			body: eventRequest(52.52, 13.41, day.Add(-24*time.Hour), day.Add(-20*time.Hour), ""),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "beyond 6 days",
+			body: eventRequest(52.52, 13.41, next, day.Add(8*24*time.Hour), ""),
+			want: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown event type",
+			body:     eventRequest(52.52, 13.41, next, next.Add(time.Hour), `,"event_type":"rodeo"`),
+			want:     http.StatusBadRequest,
+			wantBody: "Unknown event type",
+		},
+		{
+			name:     "not recorded",
+			body:     eventRequest(48.85, 2.35, next, next.Add(time.Hour), ""),
+			want:     http.StatusInternalServerError,
+			wantBody: "fixture not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postEvent(t, router, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %q", w.Body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// This file defines the handler reporting the health of the upstream weather providers.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service"
+)
+
+// UpstreamStatusHandler returns a handler for GET requests reporting the circuit
+// breaker state of the upstream weather providers.
+//
+// @Summary      Get upstream provider status
+// @Description  Returns the circuit breaker state of every upstream weather provider. Open breakers fail requests fast until their retry time.
+// @Tags         upstream
+// @Produce      json
+// @Success      200      {object}  model.UpstreamStatusResponse
+// @Router       /upstream-status [get]
+func UpstreamStatusHandler(weatherSvc *service.WeatherService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, model.UpstreamStatusResponse{
+			Breakers: weatherSvc.UpstreamStatus(),
+		})
+	}
+}
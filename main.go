@@ -3,19 +3,33 @@
 // @description     API for weather risk assessment for outdoor events.
 // @host      localhost:8080
 // @BasePath  /
+// @securityDefinitions.apikey  AdminToken
+// @in                          header
+// @name                        Authorization
+// @description                 Admin token, as "Bearer <token>"
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
 
+	"github.com/ihgazi/EventWeatherGuard/client"
+	"github.com/ihgazi/EventWeatherGuard/config"
 	_ "github.com/ihgazi/EventWeatherGuard/docs"
 	"github.com/ihgazi/EventWeatherGuard/handler"
 	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/service"
+	"github.com/ihgazi/EventWeatherGuard/store"
 )
 
 // Gin engine used to define HTTP routes and middleware
@@ -29,10 +43,107 @@ func main() {
 	logger.Init()
 	defer logger.Log.Sync()
 
+	// Load configuration from the environment
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Log.Fatal("Invalid configuration", zap.Error(err))
+	}
+
+	// Optional on-disk cache of upstream payloads that survives restarts
+	var payloadStore client.PayloadStore
+	if cfg.PayloadCachePath != "" {
+		payloadCache, err := store.OpenPayloadCache(cfg.PayloadCachePath, store.PayloadCacheOptions{
+			Expiry:   cfg.PayloadCacheMaxStale,
+			MaxBytes: cfg.PayloadCacheMaxBytes,
+		})
+		if err != nil {
+			logger.Log.Fatal("Failed to open payload cache", zap.Error(err))
+		}
+		defer payloadCache.Close()
+
+		payloadStore = payloadCache
+	}
+
+	// Optional record/replay of upstream responses. Replays run at the time the
+	// fixtures were recorded, so that the recorded events are still ahead.
+	var transport http.RoundTripper
+	now := time.Now
+	if cfg.FixtureMode != "" {
+		fixtures, err := client.NewFixtureTransport(client.FixtureMode(cfg.FixtureMode), cfg.FixtureDir, nil)
+		if err != nil {
+			logger.Log.Fatal("Failed to set up fixtures", zap.Error(err))
+		}
+		transport = fixtures
+
+		if client.FixtureMode(cfg.FixtureMode) == client.FixtureReplay {
+			recordedAt, err := fixtures.RecordedAt()
+			if err != nil {
+				logger.Log.Fatal("Failed to read fixtures", zap.Error(err))
+			}
+			if !recordedAt.IsZero() {
+				now = func() time.Time { return recordedAt }
+			}
+		}
+
+		logger.Log.Info("Upstream fixtures enabled",
+			zap.String("mode", cfg.FixtureMode),
+			zap.String("dir", cfg.FixtureDir),
+			zap.Time("now", now()),
+		)
+	}
+
+	// Weather service shared by all requests, backed by the configured providers
+	weatherSvc, err := newWeatherService(cfg, payloadStore, transport)
+	if err != nil {
+		logger.Log.Fatal("Invalid weather provider configuration", zap.Error(err))
+	}
+
+	// Classification rules, replaced by the rule set config when one is given.
+	// The config is reloaded when it changes, or on SIGHUP.
+	rules := service.NewRuleSource()
+	if cfg.RulesConfig != "" {
+		if rules, err = service.LoadRuleSource(cfg.RulesConfig); err != nil {
+			logger.Log.Fatal("Invalid rule set config", zap.Error(err))
+		}
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go rules.Watch(context.Background(), cfg.RulesReloadInterval, hup)
+	}
+
+	// Optional admin API managing rule set revisions. Once a revision is in
+	// service, including the one restored on startup, it takes precedence over
+	// the rule set config, whose changes are no longer applied.
+	var admin *service.RuleAdmin
+	if len(cfg.AdminTokens) > 0 {
+		ruleSetStore, err := store.OpenRuleSetStore(cfg.AdminDBPath)
+		if err != nil {
+			logger.Log.Fatal("Failed to open rule set store", zap.Error(err))
+		}
+		defer ruleSetStore.Close()
+
+		admin = service.NewRuleAdmin(ruleSetStore, rules)
+		if _, err := admin.Restore(); err != nil {
+			logger.Log.Fatal("Invalid stored rule set", zap.Error(err))
+		}
+	}
+	logger.Log.Info("Classification rules loaded", zap.String("version", rules.Current().Version))
+
 	// Setup API routes
 	api := router.Group("/")
 	{
-		api.POST("/event-forecast", handler.EventForecastHandler)
+		api.POST("/event-forecast", handler.EventForecastHandler(weatherSvc, rules, now))
+		api.GET("/upstream-status", handler.UpstreamStatusHandler(weatherSvc))
+	}
+	if admin != nil {
+		adminAPI := router.Group("/admin", handler.AdminAuth(cfg.AdminTokens))
+		adminAPI.GET("/rulesets", handler.ListRuleSetsHandler(admin))
+		adminAPI.POST("/rulesets", handler.CreateRuleSetHandler(admin))
+		adminAPI.GET("/rulesets/:version", handler.GetRuleSetHandler(admin))
+		adminAPI.POST("/rulesets/:version/activate", handler.ActivateRuleSetHandler(admin))
+		adminAPI.GET("/profiles", handler.ListProfilesHandler(admin))
+		adminAPI.PUT("/profiles/:name", handler.PutProfileHandler(admin))
+		adminAPI.DELETE("/profiles/:name", handler.DeleteProfileHandler(admin))
 	}
 	// Swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -44,3 +155,140 @@ func main() {
 		logger.Log.Error("Failed to run server: %v", zap.Error(err))
 	}
 }
+
+// newWeatherService builds the weather service from the configured providers,
+// merging them into a consensus forecast when more than one is configured. It
+// adds the air quality forecast when enabled, and the marine forecast for on-water events.
+func newWeatherService(
+	cfg config.Config,
+	payloadStore client.PayloadStore,
+	transport http.RoundTripper,
+) (*service.WeatherService, error) {
+	upstream := newUpstreamOptions(cfg, transport)
+
+	// A single Open-Meteo client serves both the open-meteo provider and the
+	// NWS fallback, so that they share one circuit breaker
+	forecast := openMeteoEndpoint(cfg, cfg.OpenMeteoBaseURL, payloadStore, upstream)
+	forecast.ForecastDays = cfg.OpenMeteoForecastDays
+
+	openMeteo := client.NewOpenMeteoClient(client.OpenMeteoOptions{
+		OpenMeteoEndpoint: forecast,
+		Model:             cfg.OpenMeteoModel,
+		ExtraHourly:       cfg.OpenMeteoExtraHourly,
+	})
+
+	cacheOpts := client.CacheOptions{
+		Resolution:   cfg.CacheResolution,
+		ModelCadence: cfg.CacheModelCadence,
+	}
+
+	providers := make([]client.WeatherProvider, 0, len(cfg.WeatherProviders))
+	for _, name := range cfg.WeatherProviders {
+		provider := newWeatherProvider(cfg, name, openMeteo, upstream)
+
+		if cfg.CacheEnabled {
+			provider = client.NewCachedProvider(provider, cacheOpts)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	var svc *service.WeatherService
+	if len(providers) == 1 {
+		svc = service.NewWeatherService(providers[0])
+	} else {
+		strategy, err := service.ParseMergeStrategy(cfg.ConsensusMerge)
+		if err != nil {
+			return nil, err
+		}
+		svc = service.NewConsensusWeatherService(strategy, providers...)
+	}
+
+	if cfg.AirQualityEnabled {
+		var airQuality client.AirQualityProvider = client.NewAirQualityClient(client.AirQualityOptions{
+			OpenMeteoEndpoint: openMeteoEndpoint(cfg, cfg.AirQualityBaseURL, payloadStore, upstream),
+		})
+
+		if cfg.CacheEnabled {
+			airQuality = client.NewCachedAirQualityProvider(airQuality, cacheOpts)
+		}
+
+		svc.WithAirQuality(airQuality)
+	}
+
+	var marine client.MarineProvider = client.NewMarineClient(client.MarineOptions{
+		OpenMeteoEndpoint: openMeteoEndpoint(cfg, cfg.MarineBaseURL, payloadStore, upstream),
+	})
+
+	if cfg.CacheEnabled {
+		marine = client.NewCachedMarineProvider(marine, cacheOpts)
+	}
+
+	svc.WithMarine(marine)
+
+	return svc, nil
+}
+
+// openMeteoEndpoint configures an Open-Meteo API at baseURL with the API key and
+// payload store shared by the Open-Meteo clients.
+func openMeteoEndpoint(
+	cfg config.Config,
+	baseURL string,
+	payloadStore client.PayloadStore,
+	upstream client.UpstreamOptions,
+) client.OpenMeteoEndpoint {
+	return client.OpenMeteoEndpoint{
+		BaseURL:       baseURL,
+		APIKey:        cfg.OpenMeteoAPIKey,
+		Store:         payloadStore,
+		StoreFreshFor: cfg.PayloadCacheFreshFor,
+		StoreMaxStale: cfg.PayloadCacheMaxStale,
+		Upstream:      upstream,
+	}
+}
+
+// newUpstreamOptions builds the retry and circuit breaker settings shared by the upstream clients.
+func newUpstreamOptions(cfg config.Config, transport http.RoundTripper) client.UpstreamOptions {
+	return client.UpstreamOptions{
+		Retry: client.RetryPolicy{
+			MaxAttempts: cfg.RetryMaxAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+		},
+		Breaker: client.BreakerOptions{
+			FailureThreshold: cfg.BreakerFailureThreshold,
+			Cooldown:         cfg.BreakerCooldown,
+		},
+		Transport: transport,
+	}
+}
+
+// newWeatherProvider builds the named upstream weather provider, using the
+// given Open-Meteo client for Open-Meteo forecasts.
+func newWeatherProvider(
+	cfg config.Config,
+	name string,
+	openMeteo *client.OpenMeteoClient,
+	upstream client.UpstreamOptions,
+) client.WeatherProvider {
+	switch name {
+	case config.ProviderMetNorway:
+		return client.NewMetNorwayClient(client.MetNorwayOptions{
+			BaseURL:   cfg.MetNorwayBaseURL,
+			UserAgent: cfg.MetNorwayUserAgent,
+			Upstream:  upstream,
+		})
+	case config.ProviderNWS:
+		// NWS only covers the US, other regions are served by Open-Meteo
+		return client.NewFallbackProvider(
+			client.NewNWSClient(client.NWSOptions{
+				BaseURL:   cfg.NWSBaseURL,
+				UserAgent: cfg.NWSUserAgent,
+				Upstream:  upstream,
+			}),
+			openMeteo,
+		)
+	default:
+		return openMeteo
+	}
+}
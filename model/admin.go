@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RuleSetRevision is a stored version of a rule set config.
+//
+// swagger:model RuleSetRevision
+type RuleSetRevision struct {
+	Version   string    `json:"version"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	Comment   string    `json:"comment,omitempty"`
+	// Config is the rule set config, left out of listings.
+	Config json.RawMessage `json:"config,omitempty" swaggertype:"object"`
+}
+
+// RuleSetActivation records a rule set revision being put into service.
+//
+// swagger:model RuleSetActivation
+type RuleSetActivation struct {
+	Version     string    `json:"version"`
+	Author      string    `json:"author"`
+	ActivatedAt time.Time `json:"activated_at"`
+}
+
+// RuleSetListResponse lists the stored rule set revisions and their activations.
+//
+// swagger:model RuleSetListResponse
+type RuleSetListResponse struct {
+	// Active is the version of the rule set in service.
+	Active      string              `json:"active"`
+	Revisions   []RuleSetRevision   `json:"revisions"`
+	Activations []RuleSetActivation `json:"activations"`
+}
+
+// CreateRuleSetRequest creates a new rule set revision.
+//
+// swagger:model CreateRuleSetRequest
+type CreateRuleSetRequest struct {
+	Comment string `json:"comment"`
+	// Activate puts the new revision into service right away.
+	Activate bool `json:"activate"`
+	// Config is a rule set config. Its version is assigned by the service.
+	Config json.RawMessage `json:"config" binding:"required" swaggertype:"object"`
+}
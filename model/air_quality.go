@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// AirQualityResponse mirrors the hourly part of the Open-Meteo Air Quality API response.
+type AirQualityResponse struct {
+	Hourly struct {
+		Time        []string   `json:"time"`
+		PM25        []*float64 `json:"pm2_5"`
+		PM10        []*float64 `json:"pm10"`
+		Ozone       []*float64 `json:"ozone"`
+		EuropeanAQI []*float64 `json:"european_aqi"`
+		USAQI       []*float64 `json:"us_aqi"`
+	} `json:"hourly"`
+}
+
+// AirQuality holds the air pollutant concentrations (μg/m³) and air quality
+// indices for an hour.
+//
+// swagger:model AirQuality
+type AirQuality struct {
+	PM25        float64 `json:"pm2_5"`
+	PM10        float64 `json:"pm10"`
+	Ozone       float64 `json:"ozone"`
+	EuropeanAQI int     `json:"european_aqi"`
+	USAQI       int     `json:"us_aqi"`
+}
+
+// HourlyAirQuality is the air quality forecast for a single hour.
+type HourlyAirQuality struct {
+	Time time.Time
+	AirQuality
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// MarineResponse mirrors the hourly part of the Open-Meteo Marine API response.
+type MarineResponse struct {
+	Hourly struct {
+		Time               []string   `json:"time"`
+		WaveHeight         []*float64 `json:"wave_height"`
+		WavePeriod         []*float64 `json:"wave_period"`
+		SwellWaveHeight    []*float64 `json:"swell_wave_height"`
+		SwellWavePeriod    []*float64 `json:"swell_wave_period"`
+		SwellWaveDirection []*float64 `json:"swell_wave_direction"`
+	} `json:"hourly"`
+}
+
+// Marine holds the sea state for an hour.
+//
+// swagger:model Marine
+type Marine struct {
+	WaveHeightM    float64 `json:"wave_height_m"`
+	WavePeriodS    float64 `json:"wave_period_s"`
+	SwellHeightM   float64 `json:"swell_height_m"`
+	SwellPeriodS   float64 `json:"swell_period_s"`
+	SwellDirection float64 `json:"swell_direction_deg"`
+}
+
+// HourlyMarine is the marine forecast for a single hour.
+type HourlyMarine struct {
+	Time time.Time
+	Marine
+}
@@ -0,0 +1,37 @@
+package model
+
+// MetNorwayResponse mirrors the subset of the MET Norway Locationforecast 2.0
+// compact JSON document used by the service.
+type MetNorwayResponse struct {
+	Properties struct {
+		Timeseries []MetNorwayTimestep `json:"timeseries"`
+	} `json:"properties"`
+}
+
+// MetNorwayTimestep is a single entry of the Locationforecast time series.
+// Near-term steps are hourly and carry next_1_hours; later steps are 6-hourly
+// and only carry next_6_hours.
+type MetNorwayTimestep struct {
+	Time string `json:"time"`
+	Data struct {
+		Instant struct {
+			Details struct {
+				AirTemperature   float64 `json:"air_temperature"`
+				RelativeHumidity float64 `json:"relative_humidity"`
+				WindSpeed        float64 `json:"wind_speed"`
+			} `json:"details"`
+		} `json:"instant"`
+		Next1Hours *MetNorwayPeriod `json:"next_1_hours,omitempty"`
+		Next6Hours *MetNorwayPeriod `json:"next_6_hours,omitempty"`
+	} `json:"data"`
+}
+
+// MetNorwayPeriod summarizes the weather for the period following a timestep.
+type MetNorwayPeriod struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details struct {
+		PrecipitationAmount float64 `json:"precipitation_amount"`
+	} `json:"details"`
+}
@@ -0,0 +1,50 @@
+package model
+
+// NWSPointResponse mirrors the subset of the api.weather.gov /points response
+// used to resolve a coordinate to a forecast gridpoint.
+type NWSPointResponse struct {
+	Properties struct {
+		GridID string `json:"gridId"`
+		GridX  int    `json:"gridX"`
+		GridY  int    `json:"gridY"`
+	} `json:"properties"`
+}
+
+// NWSGridpointResponse mirrors the subset of the raw gridpoint forecast layers
+// used by the service.
+type NWSGridpointResponse struct {
+	Properties struct {
+		QuantitativePrecipitation  NWSLayer        `json:"quantitativePrecipitation"`
+		ProbabilityOfPrecipitation NWSLayer        `json:"probabilityOfPrecipitation"`
+		WindSpeed                  NWSLayer        `json:"windSpeed"`
+		WindGust                   NWSLayer        `json:"windGust"`
+		Temperature                NWSLayer        `json:"temperature"`
+		RelativeHumidity           NWSLayer        `json:"relativeHumidity"`
+		ApparentTemperature        NWSLayer        `json:"apparentTemperature"`
+		SkyCover                   NWSLayer        `json:"skyCover"`
+		SnowfallAmount             NWSLayer        `json:"snowfallAmount"`
+		Visibility                 NWSLayer        `json:"visibility"`
+		Weather                    NWSWeatherLayer `json:"weather"`
+	} `json:"properties"`
+}
+
+// NWSLayer is a numeric gridpoint layer. Each value is valid for an ISO8601
+// interval such as "2026-01-14T18:00:00+00:00/PT3H".
+type NWSLayer struct {
+	UOM    string `json:"uom"`
+	Values []struct {
+		ValidTime string   `json:"validTime"`
+		Value     *float64 `json:"value"`
+	} `json:"values"`
+}
+
+// NWSWeatherLayer lists the expected weather phenomena per interval.
+type NWSWeatherLayer struct {
+	Values []struct {
+		ValidTime string `json:"validTime"`
+		Value     []struct {
+			Weather   *string `json:"weather"`
+			Intensity *string `json:"intensity"`
+		} `json:"value"`
+	} `json:"values"`
+}
@@ -1,11 +1,71 @@
 package model
 
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
 type OpenMeteoResponse struct {
-	Hourly struct {
-		Time                     []string  `json:"time"`
-		PrecipitationProbability []int     `json:"precipitation_probability"`
-		Rain                     []float64 `json:"rain"`
-		WindSpeed10m             []float64 `json:"wind_speed_10m"`
-		WeatherCode              []int     `json:"weather_code"`
-	} `json:"hourly"`
+	Hourly OpenMeteoHourly `json:"hourly"`
+}
+
+// OpenMeteoHourly is the hourly block of an Open-Meteo forecast.
+type OpenMeteoHourly struct {
+	Time                     []string  `json:"time"`
+	PrecipitationProbability []int     `json:"precipitation_probability"`
+	Rain                     []float64 `json:"rain"`
+	WindSpeed10m             []float64 `json:"wind_speed_10m"`
+	WindGusts10m             []float64 `json:"wind_gusts_10m"`
+	WeatherCode              []int     `json:"weather_code"`
+	Temperature2m            []float64 `json:"temperature_2m"`
+	RelativeHumidity2m       []float64 `json:"relative_humidity_2m"`
+	ApparentTemperature      []float64 `json:"apparent_temperature"`
+	Snowfall                 []float64 `json:"snowfall"`
+	SnowDepth                []float64 `json:"snow_depth"`
+	Visibility               []float64 `json:"visibility"`
+	Cape                     []float64 `json:"cape"`
+	LiftedIndex              []float64 `json:"lifted_index"`
+	UVIndex                  []float64 `json:"uv_index"`
+
+	// Extra holds the numeric variables without a field above, e.g. those
+	// requested on top of the required ones, keyed by variable name. Hours
+	// without a value are nil.
+	Extra map[string][]*float64 `json:"-"`
+}
+
+// UnmarshalJSON decodes the known variables into their fields and the other
+// numeric ones into Extra.
+func (h *OpenMeteoHourly) UnmarshalJSON(data []byte) error {
+	type known OpenMeteoHourly
+	if err := json.Unmarshal(data, (*known)(h)); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	t := reflect.TypeFor[OpenMeteoHourly]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(all, name)
+	}
+
+	h.Extra = nil
+	for name, raw := range all {
+		var values []*float64
+		if err := json.Unmarshal(raw, &values); err != nil {
+			// Not a numeric variable
+			continue
+		}
+
+		if h.Extra == nil {
+			h.Extra = make(map[string][]*float64, len(all))
+		}
+		h.Extra[name] = values
+	}
+
+	return nil
 }
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+
 	"github.com/relvacode/iso8601"
 )
 
@@ -13,6 +15,14 @@ type EventForecastRequest struct {
 	StartTime  *iso8601.Time `json:"start_time" binding:"required"`
 	EndTime    *iso8601.Time `json:"end_time" binding:"required"`
 	ListAlters bool          `json:"list_alternates,omitempty"`
+	// VenueType enables the marine forecast and rules for on-water events ("water").
+	VenueType VenueType `json:"venue_type,omitempty" binding:"omitempty,oneof=land water"`
+	// EventType selects the risk profile of the event, e.g. "marathon" or "wedding".
+	EventType string `json:"event_type,omitempty"`
+	// Thresholds and Weights override individual severity thresholds and weights
+	// of the profile, e.g. {"risky_wind_kmh": 20}.
+	Thresholds json.RawMessage `json:"thresholds,omitempty" swaggertype:"object"`
+	Weights    json.RawMessage `json:"weights,omitempty" swaggertype:"object"`
 }
 
 // Location represents a geographic coordinate.
@@ -22,3 +32,12 @@ type Location struct {
 	Latitude  float64 `json:"latitude" binding:"required"`
 	Longitude float64 `json:"longitude" binding:"required"`
 }
+
+// VenueType describes where an event takes place.
+type VenueType string
+
+// Venue types of an event
+const (
+	VenueLand  VenueType = "land"
+	VenueWater VenueType = "water"
+)
@@ -1,13 +1,37 @@
 package model
 
+import "time"
+
 // EventForecastResponse represents the API response for event weather forecast.
 //
 // swagger:model EventForecastResponse
 type EventForecastResponse struct {
 	Classification   string           `json:"classification"`
 	Severity         int              `json:"severity"`
+	Confidence       string           `json:"confidence"`
+	Profile          string           `json:"profile"`
+	ConfigVersion    string           `json:"config_version"`
 	Summary          string           `json:"summary"`
 	Reasons          []string         `json:"reasons"`
+	Advisories       []string         `json:"advisories,omitempty"`
 	ForecastWindow   []HourlyForecast `json:"forecast_window"`
 	AlternateWindows []EventWindow    `json:"alternate_timings,omitempty"`
 }
+
+// UpstreamStatusResponse reports the circuit breaker state of every upstream provider.
+//
+// swagger:model UpstreamStatusResponse
+type UpstreamStatusResponse struct {
+	Breakers []BreakerStatus `json:"breakers"`
+}
+
+// BreakerStatus is a snapshot of an upstream provider's circuit breaker.
+//
+// swagger:model BreakerStatus
+type BreakerStatus struct {
+	Provider            string     `json:"provider"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
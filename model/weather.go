@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // HourlyForecast represents weather data for a single hour.
 //
@@ -10,7 +13,52 @@ type HourlyForecast struct {
 	RainProb      int       `json:"rain_prob"`
 	Precipitation float64   `json:"precip_mm"`
 	WindKmh       float64   `json:"wind_kmh"`
+	GustKmh       float64   `json:"gust_kmh"`
+	WeatherCode   int       `json:"weather_code"`
 	Weather       string    `json:"weather"`
+	TemperatureC  float64   `json:"temp_c"`
+	HumidityPct   float64   `json:"humidity_pct"`
+	ApparentTempC float64   `json:"apparent_temp_c"`
+	SnowfallCm    float64   `json:"snowfall_cm"`
+	SnowDepthCm   float64   `json:"snow_depth_cm"`
+	// VisibilityM is zero when the provider does not report visibility.
+	VisibilityM float64 `json:"visibility_m"`
+	// Atmospheric instability: convective available potential energy (J/kg) and lifted index (°C)
+	CapeJkg      float64 `json:"cape_jkg"`
+	LiftedIndexC float64 `json:"lifted_index"`
+	UVIndex      float64 `json:"uv_index"`
+	// Extra holds the additional variables requested from the provider, keyed
+	// by variable name, for the hours with a value.
+	Extra map[string]float64 `json:"extra,omitempty"`
+
+	// AirQuality is only set when an air quality forecast is available for the hour.
+	AirQuality *AirQuality `json:"air_quality,omitempty"`
+	// Marine is only set for on-water events, when a marine forecast is available for the hour.
+	Marine *Marine `json:"marine,omitempty"`
+
+	// Spread is only set when several providers reported the hour.
+	Spread *ProviderSpread `json:"provider_spread,omitempty"`
+
+	// Missing lists the JSON names of the fields the provider does not forecast
+	// for the hour, which are left at zero.
+	Missing []string `json:"-"`
+}
+
+// Reports tells whether the provider forecast the field with the given JSON
+// name for the hour, as opposed to leaving it at zero.
+func (h HourlyForecast) Reports(field string) bool {
+	return !slices.Contains(h.Missing, field)
+}
+
+// ProviderSpread describes how far apart the providers' forecasts are for an hour,
+// as the difference between the highest and lowest reported value of each field.
+//
+// swagger:model ProviderSpread
+type ProviderSpread struct {
+	Providers     int     `json:"providers"`
+	RainProb      int     `json:"rain_prob"`
+	Precipitation float64 `json:"precip_mm"`
+	WindKmh       float64 `json:"wind_kmh"`
 }
 
 // EventWindow represents a specific time duration where the event occurs
@@ -0,0 +1,94 @@
+package model
+
+// WeatherCategory groups WMO weather codes describing similar phenomena.
+type WeatherCategory string
+
+// Categories of WMO weather codes, from least to most severe
+const (
+	CategoryClear        WeatherCategory = "clear"
+	CategoryCloudy       WeatherCategory = "cloudy"
+	CategoryFog          WeatherCategory = "fog"
+	CategoryDrizzle      WeatherCategory = "drizzle"
+	CategoryRain         WeatherCategory = "rain"
+	CategorySnow         WeatherCategory = "snow"
+	CategoryHeavyRain    WeatherCategory = "heavy_rain"
+	CategoryHeavySnow    WeatherCategory = "heavy_snow"
+	CategoryFreezing     WeatherCategory = "freezing"
+	CategoryThunderstorm WeatherCategory = "thunderstorm"
+)
+
+// categoryRank orders the categories by severity.
+var categoryRank = map[WeatherCategory]int{
+	CategoryClear:        0,
+	CategoryCloudy:       1,
+	CategoryFog:          2,
+	CategoryDrizzle:      3,
+	CategoryRain:         4,
+	CategorySnow:         5,
+	CategoryHeavyRain:    6,
+	CategoryHeavySnow:    7,
+	CategoryFreezing:     8,
+	CategoryThunderstorm: 9,
+}
+
+// Valid reports whether the category is known.
+func (c WeatherCategory) Valid() bool {
+	_, ok := categoryRank[c]
+	return ok
+}
+
+// WMOCode describes a WMO weather interpretation code (WMO 4677).
+type WMOCode struct {
+	Label    string
+	Category WeatherCategory
+}
+
+// WMOCodes lists the WMO weather codes reported by the providers. Sleet
+// (68, 69) is not used by Open-Meteo but is mapped from MET Norway and NWS.
+var WMOCodes = map[int]WMOCode{
+	0:  {"Clear sky", CategoryClear},
+	1:  {"Mainly clear", CategoryClear},
+	2:  {"Partly cloudy", CategoryCloudy},
+	3:  {"Overcast", CategoryCloudy},
+	45: {"Fog", CategoryFog},
+	48: {"Depositing rime fog", CategoryFog},
+	51: {"Light drizzle", CategoryDrizzle},
+	53: {"Moderate drizzle", CategoryDrizzle},
+	55: {"Dense drizzle", CategoryDrizzle},
+	56: {"Light freezing drizzle", CategoryFreezing},
+	57: {"Dense freezing drizzle", CategoryFreezing},
+	61: {"Slight rain", CategoryRain},
+	63: {"Moderate rain", CategoryRain},
+	65: {"Heavy rain", CategoryHeavyRain},
+	66: {"Light freezing rain", CategoryFreezing},
+	67: {"Heavy freezing rain", CategoryFreezing},
+	68: {"Slight sleet", CategorySnow},
+	69: {"Sleet", CategorySnow},
+	71: {"Slight snowfall", CategorySnow},
+	73: {"Moderate snowfall", CategorySnow},
+	75: {"Heavy snowfall", CategoryHeavySnow},
+	77: {"Snow grains", CategorySnow},
+	80: {"Slight rain showers", CategoryRain},
+	81: {"Moderate rain showers", CategoryRain},
+	82: {"Violent rain showers", CategoryHeavyRain},
+	85: {"Slight snow showers", CategorySnow},
+	86: {"Heavy snow showers", CategoryHeavySnow},
+	95: {"Thunderstorm", CategoryThunderstorm},
+	96: {"Thunderstorm with slight hail", CategoryThunderstorm},
+	99: {"Thunderstorm with heavy hail", CategoryThunderstorm},
+}
+
+// LookupWMO returns the description of a WMO weather code. Unknown codes are
+// reported as such and counted as clear weather.
+func LookupWMO(code int) WMOCode {
+	if c, ok := WMOCodes[code]; ok {
+		return c
+	}
+	return WMOCode{Label: "Unknown", Category: CategoryClear}
+}
+
+// WeatherCodeRank orders WMO weather codes by severity, first by category and
+// then by code, which grows with intensity within a category.
+func WeatherCodeRank(code int) int {
+	return categoryRank[LookupWMO(code).Category]*100 + code
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ihgazi/EventWeatherGuard/model"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
 )
 
 // FindTopKWindows returns the top K time windows with the most suitable weather conditions.
@@ -12,6 +13,7 @@ func FindTopKWindows(
 	hourly []model.HourlyForecast,
 	eventDuration int,
 	k int,
+	criteria cls.Criteria,
 ) []model.EventWindow {
 	if len(hourly) < eventDuration || k <= 0 {
 		return nil
@@ -25,7 +27,7 @@ func FindTopKWindows(
 		window := hourly[i : i+eventDuration]
 
 		// Fetch weather report for current window
-		result := ClassifyEvent(window)
+		result := ClassifyEvent(window, criteria)
 
 		// Ignore Unsafe / Risky time windows
 		if result.Severity >= 50 {
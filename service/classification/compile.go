@@ -0,0 +1,107 @@
+package classification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service/classification/expr"
+)
+
+// RuleDef declares a rule with a condition in the expression language and a
+// text/template description, both over the variables of the hour and the
+// thresholds, e.g.:
+//
+//	when:        precip_mm >= risky_rain_mm && wind_kmh > 25
+//	description: Wet and windy: {{printf "%.1f" .precip_mm}} mm rain at {{.time}}
+type RuleDef struct {
+	ID    string    `json:"id"`
+	Level RiskLevel `json:"level,omitempty"`
+	// Venue restricts the rule to events at the given venue type.
+	Venue       model.VenueType `json:"venue,omitempty"`
+	When        string          `json:"when,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// descriptionFuncs are the functions available to descriptions, on top of the
+// text/template built-ins such as printf.
+var descriptionFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+}
+
+// CompileRule validates a rule declaration and compiles its condition and
+// description. Invalid conditions are reported with their position.
+func CompileRule(def RuleDef) (RiskRule, error) {
+	if def.ID == "" {
+		return RiskRule{}, errors.New("id is required")
+	}
+
+	switch def.Level {
+	case Advisory, Risky, Unsafe:
+	default:
+		return RiskRule{}, fmt.Errorf("invalid level %q: must be one of Advisory, Risky, Unsafe", def.Level)
+	}
+
+	switch def.Venue {
+	case "", model.VenueLand, model.VenueWater:
+	default:
+		return RiskRule{}, fmt.Errorf("invalid venue %q: must be one of land, water", def.Venue)
+	}
+
+	when, err := expr.Compile(def.When, ruleEnv)
+	if err != nil {
+		return RiskRule{}, fmt.Errorf("when: %w", err)
+	}
+
+	if def.Description == "" {
+		return RiskRule{}, errors.New("description is required")
+	}
+
+	tmpl, err := template.New(def.ID).Funcs(descriptionFuncs).Option("missingkey=error").Parse(def.Description)
+	if err != nil {
+		return RiskRule{}, fmt.Errorf("description: %w", err)
+	}
+
+	description := func(h model.HourlyForecast, t SeverityThresholds) (string, error) {
+		var sb strings.Builder
+		err := tmpl.Execute(&sb, ruleData(ruleContext{h: &h, t: &t}))
+		return sb.String(), err
+	}
+
+	// Catch references to unknown variables before the rule is first matched
+	if _, err := description(model.HourlyForecast{}, DefaultThresholds); err != nil {
+		return RiskRule{}, fmt.Errorf("description: %w", err)
+	}
+
+	return RiskRule{
+		ID:    def.ID,
+		Level: def.Level,
+		Venue: def.Venue,
+		Matches: func(h model.HourlyForecast, t SeverityThresholds) bool {
+			return when.Eval(ruleContext{h: &h, t: &t})
+		},
+		Description: func(h model.HourlyForecast, t SeverityThresholds) string {
+			s, err := description(h, t)
+			if err != nil {
+				// Only the variables checked at compile time are referenced
+				return fmt.Sprintf("%s at %s", def.ID, h.Time.Format("15:04"))
+			}
+			return s
+		},
+	}, nil
+}
+
+// mustCompileRules compiles the built-in rules, which are known to be valid.
+func mustCompileRules(defs []RuleDef) []RiskRule {
+	rules := make([]RiskRule, len(defs))
+	for i, def := range defs {
+		rule, err := CompileRule(def)
+		if err != nil {
+			panic(fmt.Sprintf("rule %s: %v", def.ID, err))
+		}
+		rules[i] = rule
+	}
+	return rules
+}
@@ -0,0 +1,123 @@
+package classification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+func TestCompileRule(t *testing.T) {
+	rule, err := CompileRule(RuleDef{
+		ID:          "RISKY_WET_AND_WINDY",
+		Level:       Risky,
+		Venue:       model.VenueLand,
+		When:        `precip_mm >= risky_rain_mm && wind_kmh > 25 && category != "snow"`,
+		Description: `Wet and windy: {{printf "%.1f" .precip_mm}} mm rain, {{lower .weather}} at {{.time}}`,
+	})
+	if err != nil {
+		t.Fatalf("CompileRule: %v", err)
+	}
+	if rule.ID != "RISKY_WET_AND_WINDY" || rule.Level != Risky || rule.Venue != model.VenueLand {
+		t.Errorf("rule = %+v", rule)
+	}
+
+	h := model.HourlyForecast{
+		Time:          time.Date(2026, 6, 1, 14, 0, 0, 0, time.UTC),
+		Precipitation: 3.25,
+		WindKmh:       30,
+		WeatherCode:   63,
+		Weather:       "Moderate Rain",
+	}
+	if !rule.Matches(h, DefaultThresholds) {
+		t.Error("rule does not match a wet and windy hour")
+	}
+	if got, want := rule.Description(h, DefaultThresholds), "Wet and windy: 3.2 mm rain, moderate rain at 14:00"; got != want {
+		t.Errorf("Description = %q, want %q", got, want)
+	}
+
+	// The thresholds passed in are those compared with
+	strict := DefaultThresholds
+	strict.RiskyRainMM = 5
+	if rule.Matches(h, strict) {
+		t.Error("rule matches below the risky_rain_mm threshold")
+	}
+}
+
+func TestCompileRuleErrors(t *testing.T) {
+	valid := RuleDef{ID: "RISKY_HAIL", Level: Risky, When: "weather_code == 99", Description: "Hail at {{.time}}"}
+
+	tests := []struct {
+		name    string
+		modify  func(*RuleDef)
+		wantErr string
+	}{
+		{
+			name:    "missing id",
+			modify:  func(d *RuleDef) { d.ID = "" },
+			wantErr: "id is required",
+		},
+		{
+			name:    "invalid level",
+			modify:  func(d *RuleDef) { d.Level = "Severe" },
+			wantErr: `invalid level "Severe": must be one of Advisory, Risky, Unsafe`,
+		},
+		{
+			name:    "invalid venue",
+			modify:  func(d *RuleDef) { d.Venue = "air" },
+			wantErr: `invalid venue "air": must be one of land, water`,
+		},
+		{
+			name:    "missing condition",
+			modify:  func(d *RuleDef) { d.When = "" },
+			wantErr: "when: 1:1: expected a value, got end of expression",
+		},
+		{
+			name:    "unknown variable",
+			modify:  func(d *RuleDef) { d.When = "precp_mm >= 5" },
+			wantErr: `when: 1:1: unknown variable "precp_mm"`,
+		},
+		{
+			name:    "type error",
+			modify:  func(d *RuleDef) { d.When = "precip_mm >= 5 && weather" },
+			wantErr: "when: 1:16: operator && requires conditions, got bool and string",
+		},
+		{
+			name:    "misspelled category",
+			modify:  func(d *RuleDef) { d.When = `category == "thunderstrom"` },
+			wantErr: `when: 1:13: "thunderstrom" is not a valid category`,
+		},
+		{
+			name:    "missing description",
+			modify:  func(d *RuleDef) { d.Description = "" },
+			wantErr: "description is required",
+		},
+		{
+			name:    "template syntax",
+			modify:  func(d *RuleDef) { d.Description = "Hail at {{.time" },
+			wantErr: `description: template: RISKY_HAIL:1: unclosed action`,
+		},
+		{
+			name:    "unknown template variable",
+			modify:  func(d *RuleDef) { d.Description = "Hail at {{.tme}}" },
+			wantErr: `description: template: RISKY_HAIL:1:10: executing "RISKY_HAIL" at <.tme>: map has no entry for key "tme"`,
+		},
+		{
+			name:    "unknown template function",
+			modify:  func(d *RuleDef) { d.Description = "{{upper .weather}}" },
+			wantErr: `description: template: RISKY_HAIL:1: function "upper" not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := valid
+			tt.modify(&def)
+
+			_, err := CompileRule(def)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,12 +1,25 @@
 package classification
 
+import "github.com/ihgazi/EventWeatherGuard/model"
+
 type RiskLevel string
 
 // Risk levels for weather classification
 const (
-	Safe   RiskLevel = "Safe"
-	Risky  RiskLevel = "Risky"
-	Unsafe RiskLevel = "Unsafe"
+	Safe RiskLevel = "Safe"
+	// Advisory conditions call for precautions, such as shade or water
+	// stations, without putting the event at risk.
+	Advisory RiskLevel = "Advisory"
+	Risky    RiskLevel = "Risky"
+	Unsafe   RiskLevel = "Unsafe"
+)
+
+type Confidence string
+
+// Confidence in a classification, lowered when weather providers disagree
+const (
+	HighConfidence Confidence = "High"
+	LowConfidence  Confidence = "Low"
 )
 
 // Report for hourly weather risk evaluation
@@ -14,37 +27,149 @@ type HourlyEvaluation struct {
 	Level    RiskLevel
 	Reason   string
 	Severity float64
+	// Advisories lists the advisory rules matched by the hour, whatever its level.
+	Advisories []string
 }
 
 // severityThresholds for classifying weather conditions
 // Build new severityThresholds to adjust classification sensitivity
 type SeverityThresholds struct {
-	UnsafeRainMM  float64
-	UnsafeWindKmh float64
-	RiskyRainMM   float64
-	RiskyWindKmh  float64
-	RiskyRainProb int
+	UnsafeRainMM  float64 `json:"unsafe_rain_mm"`
+	UnsafeWindKmh float64 `json:"unsafe_wind_kmh"`
+	UnsafeGustKmh float64 `json:"unsafe_gust_kmh"`
+	RiskyRainMM   float64 `json:"risky_rain_mm"`
+	RiskyWindKmh  float64 `json:"risky_wind_kmh"`
+	RiskyGustKmh  float64 `json:"risky_gust_kmh"`
+	RiskyRainProb int     `json:"risky_rain_prob"`
+
+	// Heat index bands (°C), following the NWS caution / extreme caution / danger bands
+	HeatCautionC        float64 `json:"heat_caution_c"`
+	HeatExtremeCautionC float64 `json:"heat_extreme_caution_c"`
+	HeatDangerC         float64 `json:"heat_danger_c"`
+
+	// Wind chill limits (°C) for cold stress
+	UnsafeWindChillC float64 `json:"unsafe_wind_chill_c"`
+	RiskyWindChillC  float64 `json:"risky_wind_chill_c"`
+
+	// Hourly snowfall rates (cm/h)
+	UnsafeSnowfallCm float64 `json:"unsafe_snowfall_cm"`
+	RiskySnowfallCm  float64 `json:"risky_snowfall_cm"`
+
+	// Precipitation (mm/h) above which freezing rain or drizzle causes dangerous icing
+	UnsafeFreezingRainMM float64 `json:"unsafe_freezing_rain_mm"`
+
+	// Visibility limits (m); fog is reported below 1 km
+	UnsafeVisibilityM float64 `json:"unsafe_visibility_m"`
+	RiskyVisibilityM  float64 `json:"risky_visibility_m"`
+
+	// Instability above which thunderstorms may develop, even when none is forecast
+	RiskyCapeJkg      float64 `json:"risky_cape_jkg"`
+	RiskyLiftedIndexC float64 `json:"risky_lifted_index_c"`
+
+	// UV index from which sun exposure advisories are issued
+	AdvisoryUVIndex float64 `json:"advisory_uv_index"`
+
+	// Air quality index limits, on the US AQI scale (0-500) and the European AQI scale (0-100+)
+	UnsafeUSAQI         int `json:"unsafe_us_aqi"`
+	RiskyUSAQI          int `json:"risky_us_aqi"`
+	AdvisoryUSAQI       int `json:"advisory_us_aqi"`
+	UnsafeEuropeanAQI   int `json:"unsafe_european_aqi"`
+	RiskyEuropeanAQI    int `json:"risky_european_aqi"`
+	AdvisoryEuropeanAQI int `json:"advisory_european_aqi"`
+
+	// Sea state limits (m) for on-water events
+	UnsafeWaveHeightM float64 `json:"unsafe_wave_height_m"`
+	RiskyWaveHeightM  float64 `json:"risky_wave_height_m"`
+	RiskySwellHeightM float64 `json:"risky_swell_height_m"`
+
+	// Spread between providers above which their forecasts are considered in disagreement
+	DisagreeRainMM  float64 `json:"disagree_rain_mm"`
+	DisagreeWindKmh float64 `json:"disagree_wind_kmh"`
 }
 
 var DefaultThresholds = SeverityThresholds{
 	UnsafeRainMM:  10.0,
 	UnsafeWindKmh: 40.0,
+	UnsafeGustKmh: 60.0,
 	RiskyRainMM:   2.5,
 	RiskyWindKmh:  30.0,
+	RiskyGustKmh:  45.0,
 	RiskyRainProb: 40,
+
+	HeatCautionC:        27.0,
+	HeatExtremeCautionC: 32.0,
+	HeatDangerC:         39.0,
+
+	UnsafeWindChillC: -28.0,
+	RiskyWindChillC:  -10.0,
+
+	UnsafeSnowfallCm: 2.5,
+	RiskySnowfallCm:  0.5,
+
+	UnsafeFreezingRainMM: 1.0,
+
+	UnsafeVisibilityM: 200.0,
+	RiskyVisibilityM:  1000.0,
+
+	RiskyCapeJkg:      1500.0,
+	RiskyLiftedIndexC: -4.0,
+
+	AdvisoryUVIndex: 6.0,
+
+	UnsafeUSAQI:         201, // Very unhealthy
+	RiskyUSAQI:          151, // Unhealthy
+	AdvisoryUSAQI:       101, // Unhealthy for sensitive groups
+	UnsafeEuropeanAQI:   100, // Extremely poor
+	RiskyEuropeanAQI:    80,  // Very poor
+	AdvisoryEuropeanAQI: 60,  // Poor
+
+	UnsafeWaveHeightM: 2.5,
+	RiskyWaveHeightM:  1.25,
+	RiskySwellHeightM: 2.0,
+
+	DisagreeRainMM:  2.5,
+	DisagreeWindKmh: 15.0,
 }
 
 // Weights assigned to different weather factors for severity calculation
 type SeverityWeights struct {
-	Storm    []float64 // Different weights for varying WMO weather codes
-	RainMM   float64
-	RainProb float64
-	Wind     float64
+	// Minimum severity for each WMO weather code category
+	Storm      map[model.WeatherCategory]float64 `json:"storm"`
+	RainMM     float64                           `json:"rain_mm"`
+	RainProb   float64                           `json:"rain_prob"`
+	Wind       float64                           `json:"wind"`
+	Gust       float64                           `json:"gust"`
+	Heat       float64                           `json:"heat"`
+	Cold       float64                           `json:"cold"`
+	Snow       float64                           `json:"snow"`
+	Freezing   float64                           `json:"freezing"`
+	Visibility float64                           `json:"visibility"`
+	Convective float64                           `json:"convective"`
+	AirQuality float64                           `json:"air_quality"`
+	Waves      float64                           `json:"waves"`
 }
 
 var DefaultWeights = SeverityWeights{
-	Storm:    []float64{0, 0.25, 0.5, 1.0},
-	RainMM:   0.2,
-	RainProb: 0.3,
-	Wind:     0.5,
+	Storm: map[model.WeatherCategory]float64{
+		model.CategoryFog:          0.1,
+		model.CategoryDrizzle:      0.1,
+		model.CategoryRain:         0.25,
+		model.CategorySnow:         0.25,
+		model.CategoryHeavyRain:    0.5,
+		model.CategoryHeavySnow:    0.5,
+		model.CategoryFreezing:     0.5,
+		model.CategoryThunderstorm: 1.0,
+	},
+	RainMM:     0.2,
+	RainProb:   0.3,
+	Wind:       0.5,
+	Gust:       0.3,
+	Heat:       0.4,
+	Cold:       0.4,
+	Snow:       0.4,
+	Freezing:   0.5,
+	Visibility: 0.3,
+	Convective: 0.25,
+	AirQuality: 0.4,
+	Waves:      0.5,
 }
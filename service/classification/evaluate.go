@@ -9,18 +9,34 @@ import (
 	"github.com/ihgazi/EventWeatherGuard/model"
 )
 
-// EvaluateHourlyRisk assesses the weather risk for a single hourly forecast.
+// EvaluateHourlyRisk assesses the weather risk for a single hourly forecast,
+// using the rules of the criteria, filtered and tuned by its profile.
 // It returns an HourlyEvaluation containing the risk level, reason, and severity score.
+// Advisory rules are reported separately and only set the level when no other rule matches.
+// Rules restricted to another venue type are skipped.
 func EvaluateHourlyRisk(
 	h model.HourlyForecast,
-	t SeverityThresholds,
-	w SeverityWeights,
+	c Criteria,
 ) HourlyEvaluation {
+	p := c.Profile
+	t, w := p.Thresholds, p.Weights
 
 	var selectedRule *RiskRule
+	var advisories []string
 
 	// Find most severe matching rule
-	for _, rule := range ClassificationRules {
+	for _, rule := range c.Rules {
+		if (rule.Venue != "" && rule.Venue != c.Venue) || !p.Enabled(rule.ID) {
+			continue
+		}
+
+		if rule.Level == Advisory {
+			if rule.Matches(h, t) {
+				advisories = append(advisories, rule.Description(h, t))
+			}
+			continue
+		}
+
 		if rule.Matches(h, t) {
 			if selectedRule == nil ||
 				riskPriority(rule.Level) > riskPriority(selectedRule.Level) {
@@ -31,9 +47,19 @@ func EvaluateHourlyRisk(
 
 	if selectedRule != nil {
 		return HourlyEvaluation{
-			Level:    selectedRule.Level,
-			Reason:   selectedRule.Description(h),
-			Severity: computeSeverity(h, w, t),
+			Level:      selectedRule.Level,
+			Reason:     selectedRule.Description(h, t),
+			Severity:   computeSeverity(h, w, t),
+			Advisories: advisories,
+		}
+	}
+
+	if len(advisories) > 0 {
+		return HourlyEvaluation{
+			Level:      Advisory,
+			Reason:     advisories[0],
+			Severity:   computeSeverity(h, w, t),
+			Advisories: advisories,
 		}
 	}
 
@@ -44,42 +70,90 @@ func EvaluateHourlyRisk(
 	}
 }
 
+// EvaluateConfidence reports low confidence for an hour when the weather providers
+// disagree strongly about rain or wind. Hours backed by a single provider keep high confidence.
+func EvaluateConfidence(h model.HourlyForecast, t SeverityThresholds) Confidence {
+	if h.Spread == nil {
+		return HighConfidence
+	}
+
+	if h.Spread.Precipitation >= t.DisagreeRainMM || h.Spread.WindKmh >= t.DisagreeWindKmh {
+		return LowConfidence
+	}
+
+	return HighConfidence
+}
+
 // computeSeverity calculates a normalized severity score (0.0–1.0) for the hour
-// based on precipitation, wind, and rain probability, weighted by the provided configuration.
+// based on precipitation, wind, gusts, rain probability, heat, cold, snowfall and
+// freezing precipitation, visibility, instability, air quality and sea state,
+// weighted by the provided configuration.
 func computeSeverity(
 	h model.HourlyForecast,
 	w SeverityWeights,
 	t SeverityThresholds) float64 {
 	sr := min(1.0, h.Precipitation/t.UnsafeRainMM)
 	sw := min(1.0, h.WindKmh/t.UnsafeWindKmh)
+	sg := min(1.0, h.GustKmh/t.UnsafeGustKmh)
 	sp := min(1.0, float64(h.RainProb)/100.0)
 
-	score := w.RainMM*sr + w.Wind*sw + w.RainProb*sp
+	// Heat only contributes from the caution band upwards
+	hi := HeatIndexC(h.TemperatureC, h.HumidityPct)
+	sh := max(0.0, min(1.0, (hi-t.HeatCautionC)/(t.HeatDangerC-t.HeatCautionC)))
+
+	// Cold only contributes once the wind chill reaches the risky limit
+	wc := WindChillC(h.TemperatureC, h.WindKmh)
+	sc := max(0.0, min(1.0, (t.RiskyWindChillC-wc)/(t.RiskyWindChillC-t.UnsafeWindChillC)))
+
+	ss := min(1.0, h.SnowfallCm/t.UnsafeSnowfallCm)
+
+	// Freezing precipitation only contributes in freezing rain or drizzle
+	sf := 0.0
+	if model.LookupWMO(h.WeatherCode).Category == model.CategoryFreezing {
+		sf = min(1.0, h.Precipitation/t.UnsafeFreezingRainMM)
+	}
+
+	// Visibility only contributes below the risky limit, when it is reported
+	sv := 0.0
+	if h.VisibilityM > 0 {
+		sv = max(0.0, min(1.0, (t.RiskyVisibilityM-h.VisibilityM)/(t.RiskyVisibilityM-t.UnsafeVisibilityM)))
+	}
+
+	si := min(1.0, h.CapeJkg/t.RiskyCapeJkg)
+
+	// Air quality contributes from the advisory level upwards, when it is available
+	sa := 0.0
+	if h.AirQuality != nil {
+		sa = max(0.0, min(1.0, float64(h.AirQuality.USAQI-t.AdvisoryUSAQI)/float64(t.UnsafeUSAQI-t.AdvisoryUSAQI)))
+	}
+
+	// Sea state is only forecast for on-water events
+	sm := 0.0
+	if h.Marine != nil {
+		sm = min(1.0, h.Marine.WaveHeightM/t.UnsafeWaveHeightM)
+	}
+
+	score := w.RainMM*sr + w.Wind*sw + w.Gust*sg + w.RainProb*sp + w.Heat*sh + w.Cold*sc + w.Snow*ss + w.Freezing*sf +
+		w.Visibility*sv + w.Convective*si + w.AirQuality*sa + w.Waves*sm
 	score = max(score, wmoCap(h, w))
 
 	return min(1.0, score)
 }
 
-// wmoCap returns a minimum severity score based on the WMO weather code label.
+// wmoCap returns a minimum severity score based on the category of the WMO
+// weather code. Categories without a weight impose no minimum.
 func wmoCap(h model.HourlyForecast, w SeverityWeights) float64 {
-	switch h.Weather {
-	case "Thunderstorm":
-		return w.Storm[3]
-	case "Heavy Rain":
-		return w.Storm[2]
-	case "Rain Showers":
-		return w.Storm[1]
-	default:
-		return w.Storm[0]
-	}
+	return w.Storm[model.LookupWMO(h.WeatherCode).Category]
 }
 
 // Evaluate priority of different risk levels
 func riskPriority(level RiskLevel) int {
 	switch level {
 	case Unsafe:
-		return 3
+		return 4
 	case Risky:
+		return 3
+	case Advisory:
 		return 2
 	case Safe:
 		return 1
@@ -0,0 +1,135 @@
+// Package expr implements the expression language of declarative classification rules.
+//
+// Expressions combine variables, number, string and boolean literals with the
+// usual operators, e.g. `precip_mm >= 5 && wind_kmh > 25`:
+//
+//	||                      logical or
+//	&&                      logical and
+//	== != < <= > >=         comparison (numbers; == and != also strings and booleans)
+//	+ -                     addition and subtraction
+//	* /                     multiplication and division
+//	! -                     logical not and negation
+//
+// Expressions are parsed and type-checked once by Compile against an Env
+// declaring the variables, and then evaluated against any number of contexts.
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is the type of an expression or variable.
+type Type int
+
+// Types of values
+const (
+	Number Type = iota + 1
+	Bool
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Number:
+		return "number"
+	case Bool:
+		return "bool"
+	case String:
+		return "string"
+	default:
+		return "invalid"
+	}
+}
+
+// Var declares a variable of an Env. Get returns its value in a context, a
+// float64, bool or string matching its type.
+type Var[C any] struct {
+	Name string
+	Type Type
+	Get  func(c C) any
+	// Valid optionally checks the string literals the variable is compared
+	// with, so that a misspelled value is an error rather than never equal.
+	Valid func(s string) bool
+}
+
+// Env declares the variables available to expressions evaluated against a
+// context of type C.
+type Env[C any] struct {
+	vars map[string]Var[C]
+}
+
+// NewEnv returns an Env declaring the given variables.
+func NewEnv[C any](vars ...Var[C]) *Env[C] {
+	env := &Env[C]{vars: make(map[string]Var[C], len(vars))}
+	for _, v := range vars {
+		env.vars[v.Name] = v
+	}
+	return env
+}
+
+// Lookup returns the variable with the given name.
+func (e *Env[C]) Lookup(name string) (Var[C], bool) {
+	v, ok := e.vars[name]
+	return v, ok
+}
+
+// Position is a position in the source of an expression, both 1-based.
+type Position struct {
+	Line int
+	Col  int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// Error reports an invalid expression and the position of the problem.
+type Error struct {
+	Pos Position
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Program is a compiled boolean expression.
+type Program[C any] struct {
+	src  string
+	root node[C]
+}
+
+// Compile parses and type-checks a boolean expression against the variables
+// of the environment.
+func Compile[C any](src string, env *Env[C]) (*Program[C], error) {
+	p := &parser[C]{lex: newLexer(src), env: env}
+
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if root.typ() != Bool {
+		return nil, &Error{Pos: position(src, 0), Msg: fmt.Sprintf("expression must be a condition, got %s", root.typ())}
+	}
+
+	return &Program[C]{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression in the given context.
+func (p *Program[C]) Eval(c C) bool {
+	return p.root.eval(c).(bool)
+}
+
+// String returns the source of the expression.
+func (p *Program[C]) String() string {
+	return p.src
+}
+
+// position converts a byte offset in src into a line and column.
+func position(src string, offset int) Position {
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := offset - strings.LastIndex(before, "\n")
+	return Position{Line: line, Col: col}
+}
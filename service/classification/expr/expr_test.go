@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"testing"
+)
+
+type testContext struct {
+	x, y  float64
+	ok    bool
+	s     string
+	calls *int
+}
+
+var testEnv = NewEnv(
+	Var[testContext]{Name: "x", Type: Number, Get: func(c testContext) any { return c.x }},
+	Var[testContext]{Name: "y", Type: Number, Get: func(c testContext) any { return c.y }},
+	Var[testContext]{Name: "ok", Type: Bool, Get: func(c testContext) any { return c.ok }},
+	Var[testContext]{Name: "s", Type: String, Get: func(c testContext) any { return c.s },
+		Valid: func(s string) bool { return s == "a" || s == "b" }},
+	Var[testContext]{Name: "label", Type: String, Get: func(c testContext) any { return c.s }},
+	// counted counts its evaluations, to check short-circuiting
+	Var[testContext]{Name: "counted", Type: Bool, Get: func(c testContext) any {
+		*c.calls++
+		return true
+	}},
+)
+
+func TestEval(t *testing.T) {
+	ctx := testContext{x: 2, y: 5, s: "a"}
+
+	tests := []struct {
+		src  string
+		ok   bool
+		want bool
+	}{
+		// Precedence and associativity
+		{src: "1 + 2 * 3 == 7", want: true},
+		{src: "(1 + 2) * 3 == 9", want: true},
+		{src: "10 - 4 - 3 == 3", want: true},
+		{src: "8 / 4 / 2 == 1", want: true},
+		{src: "-x * 2 == -4", want: true},
+		{src: "- -x == x", want: true},
+		{src: "x + 1 < y && y < 6", want: true},
+		{src: "ok || x > 1 && false", want: false},
+		{src: "ok || x > 1 && false", ok: true, want: true},
+		{src: "(ok || x > 1) && false", ok: true, want: false},
+		{src: "!ok && true", want: true},
+		{src: "!(ok || true)", want: false},
+
+		// Comparisons
+		{src: "x <= 2 && x >= 2 && x != y", want: true},
+		{src: `s == "a" && s != "b"`, want: true},
+		{src: `label == "anything"`, want: false},
+		{src: "ok == false", want: true},
+
+		// Layout
+		{src: "x > 1 &&\n\ty > 4", want: true},
+		{src: "x>.5", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Compile(tt.src, testEnv)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+
+			c := ctx
+			c.ok = tt.ok
+			if got := prog.Eval(c); got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+			if got := prog.String(); got != tt.src {
+				t.Errorf("String = %q, want the source", got)
+			}
+		})
+	}
+}
+
+func TestEvalShortCircuit(t *testing.T) {
+	tests := []struct {
+		src   string
+		want  bool
+		calls int
+	}{
+		{src: "false && counted", want: false, calls: 0},
+		{src: "true || counted", want: true, calls: 0},
+		{src: "true && counted", want: true, calls: 1},
+		{src: "false || counted", want: true, calls: 1},
+		{src: "false && counted || counted", want: true, calls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Compile(tt.src, testEnv)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+
+			var calls int
+			if got := prog.Eval(testContext{calls: &calls}); got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("counted evaluated %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		// Syntax
+		{src: "", wantErr: "1:1: expected a value, got end of expression"},
+		{src: "x > 1 && y < 2 && )", wantErr: `1:19: expected a value, got ")"`},
+		{src: "x > 1 y", wantErr: `1:7: unexpected "y"`},
+		{src: "(x > 1", wantErr: "1:7: expected ) to close ( at 1:1, got end of expression"},
+		{src: "x > 1)", wantErr: `1:6: unexpected ")"`},
+		{src: "x > 1.2.3", wantErr: `1:5: invalid number "1.2.3"`},
+		{src: `s == "a`, wantErr: "1:6: unterminated string"},
+		{src: "x # 1", wantErr: "1:3: unexpected character '#'"},
+		{src: "x > 1 &&\n  y >", wantErr: "2:6: expected a value, got end of expression"},
+
+		// Chained comparisons
+		{src: "1 < x < 3", wantErr: "1:7: comparisons cannot be chained, use &&"},
+		{src: "x == y == ok", wantErr: "1:8: comparisons cannot be chained, use &&"},
+
+		// Unknown variables
+		{src: "z > 1", wantErr: `1:1: unknown variable "z"`},
+		{src: "x > 1 &&\n  zz", wantErr: `2:3: unknown variable "zz"`},
+
+		// Types
+		{src: "x", wantErr: "1:1: expression must be a condition, got number"},
+		{src: "x + ok > 1", wantErr: "1:3: operator + requires numbers, got number and bool"},
+		{src: "x && ok", wantErr: "1:3: operator && requires conditions, got number and bool"},
+		{src: "ok || s", wantErr: "1:4: operator || requires conditions, got bool and string"},
+		{src: `s < "b"`, wantErr: "1:3: operator < requires numbers, got string and string"},
+		{src: "s == 1", wantErr: "1:3: cannot compare string with number"},
+		{src: "!x", wantErr: "1:1: operator ! requires a condition, got number"},
+		{src: "-ok", wantErr: "1:1: operator - requires a number, got bool"},
+
+		// Values of the variable
+		{src: `s == "c"`, wantErr: `1:6: "c" is not a valid s`},
+		{src: `ok && "c" != s`, wantErr: `1:7: "c" is not a valid s`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, testEnv)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
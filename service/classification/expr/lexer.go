@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+// Kinds of tokens
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	// text is the operator or identifier, or the unquoted string.
+	text string
+	num  float64
+	// offset is the byte offset of the token in the source.
+	offset int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators lists the operators, longest first so that "<=" is not read as "<".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "!"}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src}
+}
+
+func (l *lexer) errorf(offset int, format string, args ...any) error {
+	return &Error{Pos: position(l.src, offset), Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next token of the source.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+
+	start := l.pos
+	if start == len(l.src) {
+		return token{kind: tokEOF, offset: start}, nil
+	}
+
+	rest := l.src[start:]
+	r, _ := utf8.DecodeRuneInString(rest)
+
+	switch {
+	case r == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", offset: start}, nil
+	case r == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", offset: start}, nil
+	case r == '"':
+		return l.string()
+	case r == '.' || (r >= '0' && r <= '9'):
+		return l.number()
+	case r == '_' || unicode.IsLetter(r):
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if end < 0 {
+			end = len(rest)
+		}
+		l.pos += end
+		return token{kind: tokIdent, text: rest[:end], offset: start}, nil
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			l.pos += len(op)
+			return token{kind: tokOp, text: op, offset: start}, nil
+		}
+	}
+
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	end := start
+	for end < len(l.src) && (l.src[end] == '.' || (l.src[end] >= '0' && l.src[end] <= '9')) {
+		end++
+	}
+
+	text := l.src[start:end]
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, l.errorf(start, "invalid number %q", text)
+	}
+
+	l.pos = end
+	return token{kind: tokNumber, text: text, num: n, offset: start}, nil
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	end := strings.IndexByte(l.src[start+1:], '"')
+	if end < 0 {
+		return token{}, l.errorf(start, "unterminated string")
+	}
+
+	l.pos = start + end + 2
+	return token{kind: tokString, text: l.src[start+1 : start+1+end], offset: start}, nil
+}
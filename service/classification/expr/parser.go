@@ -0,0 +1,344 @@
+package expr
+
+import "slices"
+
+// parser is a recursive descent parser, building type-checked nodes. From the
+// lowest precedence to the highest:
+//
+//	or      = and { "||" and }
+//	and     = compare { "&&" compare }
+//	compare = sum [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) sum ]
+//	sum     = product { ( "+" | "-" ) product }
+//	product = unary { ( "*" | "/" ) unary }
+//	unary   = ( "!" | "-" ) unary | primary
+//	primary = number | string | "true" | "false" | identifier | "(" or ")"
+type parser[C any] struct {
+	lex *lexer
+	env *Env[C]
+	tok token
+}
+
+func (p *parser[C]) parse() (node[C], error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf(p.tok, "unexpected %s", p.tok)
+	}
+
+	return n, nil
+}
+
+func (p *parser[C]) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser[C]) errorf(tok token, format string, args ...any) error {
+	return p.lex.errorf(tok.offset, format, args...)
+}
+
+// isOp reports whether the current token is one of the operators.
+func (p *parser[C]) isOp(ops ...string) bool {
+	return p.tok.kind == tokOp && slices.Contains(ops, p.tok.text)
+}
+
+func (p *parser[C]) or() (node[C], error) {
+	return p.binary(p.and, "||")
+}
+
+func (p *parser[C]) and() (node[C], error) {
+	return p.binary(p.compare, "&&")
+}
+
+func (p *parser[C]) sum() (node[C], error) {
+	return p.binary(p.product, "+", "-")
+}
+
+func (p *parser[C]) product() (node[C], error) {
+	return p.binary(p.unary, "*", "/")
+}
+
+// binary parses a left-associative sequence of operands joined by the operators.
+func (p *parser[C]) binary(operand func() (node[C], error), ops ...string) (node[C], error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp(ops...) {
+		op := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		if left, err = p.combine(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser[C]) compare() (node[C], error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isOp("==", "!=", "<", "<=", ">", ">=") {
+		return left, nil
+	}
+
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	right, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isOp("==", "!=", "<", "<=", ">", ">=") {
+		return nil, p.errorf(p.tok, "comparisons cannot be chained, use &&")
+	}
+
+	return p.combine(op, left, right)
+}
+
+// combine type-checks a binary operation.
+func (p *parser[C]) combine(op token, left, right node[C]) (node[C], error) {
+	lt, rt := left.typ(), right.typ()
+
+	switch op.text {
+	case "&&", "||":
+		if lt != Bool || rt != Bool {
+			return nil, p.errorf(op, "operator %s requires conditions, got %s and %s", op.text, lt, rt)
+		}
+		return &logical[C]{op: op.text, left: left, right: right}, nil
+	case "+", "-", "*", "/":
+		if lt != Number || rt != Number {
+			return nil, p.errorf(op, "operator %s requires numbers, got %s and %s", op.text, lt, rt)
+		}
+		return &arith[C]{op: op.text, left: left, right: right}, nil
+	case "==", "!=":
+		if lt != rt {
+			return nil, p.errorf(op, "cannot compare %s with %s", lt, rt)
+		}
+		if err := p.checkValue(left, right); err != nil {
+			return nil, err
+		}
+		if err := p.checkValue(right, left); err != nil {
+			return nil, err
+		}
+		return &compare[C]{op: op.text, left: left, right: right}, nil
+	default:
+		if lt != Number || rt != Number {
+			return nil, p.errorf(op, "operator %s requires numbers, got %s and %s", op.text, lt, rt)
+		}
+		return &compare[C]{op: op.text, left: left, right: right}, nil
+	}
+}
+
+// checkValue checks a string literal compared with a variable against the
+// values of the variable.
+func (p *parser[C]) checkValue(x, y node[C]) error {
+	v, ok := x.(*variable[C])
+	if !ok || v.v.Valid == nil {
+		return nil
+	}
+	lit, ok := y.(*literal[C])
+	if !ok || lit.t != String {
+		return nil
+	}
+
+	if s := lit.v.(string); !v.v.Valid(s) {
+		return p.errorf(lit.tok, "%q is not a valid %s", s, v.v.Name)
+	}
+	return nil
+}
+
+func (p *parser[C]) unary() (node[C], error) {
+	if !p.isOp("!", "-") {
+		return p.primary()
+	}
+
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	if op.text == "!" {
+		if x.typ() != Bool {
+			return nil, p.errorf(op, "operator ! requires a condition, got %s", x.typ())
+		}
+		return &not[C]{x: x}, nil
+	}
+
+	if x.typ() != Number {
+		return nil, p.errorf(op, "operator - requires a number, got %s", x.typ())
+	}
+	return &neg[C]{x: x}, nil
+}
+
+func (p *parser[C]) primary() (node[C], error) {
+	tok := p.tok
+
+	switch tok.kind {
+	case tokNumber:
+		return &literal[C]{t: Number, v: tok.num}, p.advance()
+	case tokString:
+		return &literal[C]{t: String, v: tok.text, tok: tok}, p.advance()
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literal[C]{t: Bool, v: tok.text == "true"}, p.advance()
+		}
+
+		v, ok := p.env.Lookup(tok.text)
+		if !ok {
+			return nil, p.errorf(tok, "unknown variable %q", tok.text)
+		}
+		return &variable[C]{v: v}, p.advance()
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf(p.tok, "expected ) to close ( at %s, got %s", position(p.lex.src, tok.offset), p.tok)
+		}
+		return n, p.advance()
+	default:
+		return nil, p.errorf(tok, "expected a value, got %s", tok)
+	}
+}
+
+// node is a type-checked expression.
+type node[C any] interface {
+	typ() Type
+	eval(c C) any
+}
+
+type literal[C any] struct {
+	t Type
+	v any
+	// tok is the token of a string literal, for errors about its value.
+	tok token
+}
+
+func (n *literal[C]) typ() Type  { return n.t }
+func (n *literal[C]) eval(C) any { return n.v }
+
+type variable[C any] struct {
+	v Var[C]
+}
+
+func (n *variable[C]) typ() Type    { return n.v.Type }
+func (n *variable[C]) eval(c C) any { return n.v.Get(c) }
+
+type not[C any] struct {
+	x node[C]
+}
+
+func (n *not[C]) typ() Type    { return Bool }
+func (n *not[C]) eval(c C) any { return !n.x.eval(c).(bool) }
+
+type neg[C any] struct {
+	x node[C]
+}
+
+func (n *neg[C]) typ() Type    { return Number }
+func (n *neg[C]) eval(c C) any { return -n.x.eval(c).(float64) }
+
+// logical evaluates && and || with short-circuiting.
+type logical[C any] struct {
+	op          string
+	left, right node[C]
+}
+
+func (n *logical[C]) typ() Type { return Bool }
+
+func (n *logical[C]) eval(c C) any {
+	l := n.left.eval(c).(bool)
+	if n.op == "&&" {
+		return l && n.right.eval(c).(bool)
+	}
+	return l || n.right.eval(c).(bool)
+}
+
+type arith[C any] struct {
+	op          string
+	left, right node[C]
+}
+
+func (n *arith[C]) typ() Type { return Number }
+
+func (n *arith[C]) eval(c C) any {
+	l, r := n.left.eval(c).(float64), n.right.eval(c).(float64)
+
+	switch n.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	default:
+		return l / r
+	}
+}
+
+type compare[C any] struct {
+	op          string
+	left, right node[C]
+}
+
+func (n *compare[C]) typ() Type { return Bool }
+
+func (n *compare[C]) eval(c C) any {
+	l, r := n.left.eval(c), n.right.eval(c)
+
+	switch n.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+
+	a, b := l.(float64), r.(float64)
+	switch n.op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
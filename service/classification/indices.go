@@ -0,0 +1,44 @@
+package classification
+
+import "math"
+
+// HeatIndexC returns the heat index ("feels like" temperature) in °C for the
+// given air temperature in °C and relative humidity in %.
+//
+// It uses the NWS formulation: Steadman's simple formula below about 80°F, and
+// the Rothfusz regression with its humidity adjustments above.
+func HeatIndexC(tempC, humidity float64) float64 {
+	t := tempC*9/5 + 32
+	rh := humidity
+
+	hi := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+
+	if (hi+t)/2 >= 80 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+			0.22475541*t*rh - 0.00683783*t*t -
+			0.05481717*rh*rh + 0.00122874*t*t*rh +
+			0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+		switch {
+		case rh < 13 && t >= 80 && t <= 112:
+			hi -= ((13 - rh) / 4) * math.Sqrt((17-math.Abs(t-95))/17)
+		case rh > 85 && t >= 80 && t <= 87:
+			hi += ((rh - 85) / 10) * ((87 - t) / 5)
+		}
+	}
+
+	return (hi - 32) * 5 / 9
+}
+
+// WindChillC returns the wind chill temperature in °C for the given air
+// temperature in °C and wind speed in km/h, using the Environment Canada / NWS
+// formula. Outside its validity range (above 10°C or below 4.8 km/h of wind)
+// the air temperature is returned unchanged.
+func WindChillC(tempC, windKmh float64) float64 {
+	if tempC > 10 || windKmh < 4.8 {
+		return tempC
+	}
+
+	v := math.Pow(windKmh, 0.16)
+	return 13.12 + 0.6215*tempC - 11.37*v + 0.3965*tempC*v
+}
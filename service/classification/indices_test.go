@@ -0,0 +1,69 @@
+package classification
+
+import (
+	"math"
+	"testing"
+)
+
+func fahrenheitToC(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+func cToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// The expected values are those of the NWS heat index chart, in °F. The chart
+// is rounded to whole degrees, so values within a degree are accepted.
+func TestHeatIndexC(t *testing.T) {
+	tests := []struct {
+		tempF, humidity, wantF float64
+	}{
+		// Steadman's simple formula
+		{tempF: 70, humidity: 50, wantF: 69},
+		{tempF: 80, humidity: 40, wantF: 80},
+		// Rothfusz regression
+		{tempF: 90, humidity: 40, wantF: 91},
+		{tempF: 90, humidity: 60, wantF: 100},
+		{tempF: 90, humidity: 70, wantF: 106},
+		{tempF: 96, humidity: 65, wantF: 121},
+		{tempF: 100, humidity: 40, wantF: 109},
+		{tempF: 104, humidity: 55, wantF: 137},
+	}
+
+	for _, tt := range tests {
+		got := cToFahrenheit(HeatIndexC(fahrenheitToC(tt.tempF), tt.humidity))
+		if math.Abs(got-tt.wantF) > 1 {
+			t.Errorf("HeatIndexC(%v°F, %v%%) = %.1f°F, want %v°F", tt.tempF, tt.humidity, got, tt.wantF)
+		}
+	}
+}
+
+// The expected values are those of the NWS wind chill chart, in °F and mph,
+// which the metric formula matches within a degree.
+func TestWindChillC(t *testing.T) {
+	tests := []struct {
+		tempF, windMph, wantF float64
+	}{
+		{tempF: 40, windMph: 5, wantF: 36},
+		{tempF: 30, windMph: 5, wantF: 25},
+		{tempF: 20, windMph: 10, wantF: 9},
+		{tempF: 0, windMph: 15, wantF: -19},
+		{tempF: -10, windMph: 30, wantF: -39},
+		{tempF: -20, windMph: 20, wantF: -48},
+	}
+
+	for _, tt := range tests {
+		got := cToFahrenheit(WindChillC(fahrenheitToC(tt.tempF), tt.windMph*1.609344))
+		if math.Abs(got-tt.wantF) > 1 {
+			t.Errorf("WindChillC(%v°F, %v mph) = %.1f°F, want %v°F", tt.tempF, tt.windMph, got, tt.wantF)
+		}
+	}
+
+	// Outside the validity range the air temperature is returned
+	for _, c := range [][2]float64{{15, 30}, {-5, 3}} {
+		if got := WindChillC(c[0], c[1]); got != c[0] {
+			t.Errorf("WindChillC(%v°C, %v km/h) = %v, want the air temperature", c[0], c[1], got)
+		}
+	}
+}
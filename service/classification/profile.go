@@ -0,0 +1,211 @@
+package classification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Profile bundles the thresholds, weights and rules used to classify a type of event.
+type Profile struct {
+	Name       string
+	Thresholds SeverityThresholds
+	Weights    SeverityWeights
+	// Rules lists the IDs of the enabled rules. Every rule is enabled when empty.
+	Rules []string
+	// Exclude lists the IDs of the rules disabled for the profile.
+	Exclude []string
+}
+
+// DefaultProfile classifies events of unknown type with the default configuration.
+var DefaultProfile = Profile{
+	Name:       "default",
+	Thresholds: DefaultThresholds,
+	Weights:    DefaultWeights,
+}
+
+// Profiles lists the built-in event-type profiles, keyed by event type.
+var Profiles = builtinProfiles(DefaultProfile)
+
+// builtinProfiles returns the built-in event-type profiles, each adjusting the
+// thresholds and weights of the base profile.
+func builtinProfiles(base Profile) map[string]Profile {
+	return map[string]Profile{
+		"concert":   concertProfile(base),
+		"marathon":  marathonProfile(base),
+		"wedding":   weddingProfile(base),
+		"football":  footballProfile(base),
+		"fireworks": fireworksProfile(base),
+		"market":    marketProfile(base),
+	}
+}
+
+// Enabled reports whether the rule is enabled in the profile.
+func (p Profile) Enabled(ruleID string) bool {
+	return (len(p.Rules) == 0 || slices.Contains(p.Rules, ruleID)) && !slices.Contains(p.Exclude, ruleID)
+}
+
+// WithOverrides returns a copy of the profile with the given thresholds and
+// weights, JSON objects of which only the present fields are applied. The
+// resulting thresholds and weights are validated.
+func (p Profile) WithOverrides(thresholds, weights json.RawMessage) (Profile, error) {
+	p.Weights.Storm = maps.Clone(p.Weights.Storm)
+
+	if len(thresholds) > 0 {
+		if err := decodeStrict(thresholds, &p.Thresholds); err != nil {
+			return Profile{}, fmt.Errorf("invalid thresholds: %w", err)
+		}
+	}
+	if len(weights) > 0 {
+		if err := decodeStrict(weights, &p.Weights); err != nil {
+			return Profile{}, fmt.Errorf("invalid weights: %w", err)
+		}
+	}
+
+	if err := p.validate(); err != nil {
+		return Profile{}, err
+	}
+
+	return p, nil
+}
+
+// validate validates the thresholds and weights of the profile.
+func (p Profile) validate() error {
+	if err := p.Thresholds.Validate(); err != nil {
+		return fmt.Errorf("invalid thresholds: %w", err)
+	}
+	if err := p.Weights.Validate(); err != nil {
+		return fmt.Errorf("invalid weights: %w", err)
+	}
+
+	return nil
+}
+
+// Config returns the profile as a config, with every threshold and weight set.
+func (p Profile) Config() ProfileConfig {
+	// Both are plain structs, which cannot fail to marshal
+	thresholds, _ := json.Marshal(p.Thresholds)
+	weights, _ := json.Marshal(p.Weights)
+
+	return ProfileConfig{Thresholds: thresholds, Weights: weights, Rules: p.Rules, Exclude: p.Exclude}
+}
+
+// decodeStrict decodes a JSON object over v, rejecting unknown fields and
+// anything following the object.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// cloneWeights returns a copy of the weights that can be modified.
+func cloneWeights(w SeverityWeights) SeverityWeights {
+	w.Storm = maps.Clone(w.Storm)
+	return w
+}
+
+// Stages, lighting rigs and PA towers are sensitive to wind and gusts.
+func concertProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.RiskyWindKmh, t.UnsafeWindKmh = 25, 35
+	t.RiskyGustKmh, t.UnsafeGustKmh = 40, 55
+
+	w := cloneWeights(base.Weights)
+	w.Wind, w.Gust = 0.6, 0.4
+
+	return Profile{Name: "concert", Thresholds: t, Weights: w}
+}
+
+// Runners tolerate rain but are exposed to heat stress and air pollution for hours.
+func marathonProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.HeatCautionC, t.HeatExtremeCautionC, t.HeatDangerC = 24, 28, 32
+	t.RiskyRainMM, t.UnsafeRainMM = 5, 15
+	t.RiskyRainProb = 80
+	t.AdvisoryUSAQI, t.RiskyUSAQI, t.UnsafeUSAQI = 76, 101, 151
+	t.AdvisoryEuropeanAQI, t.RiskyEuropeanAQI, t.UnsafeEuropeanAQI = 40, 60, 80
+
+	w := cloneWeights(base.Weights)
+	w.Heat, w.AirQuality = 0.7, 0.6
+	w.RainMM, w.RainProb = 0.1, 0.1
+
+	return Profile{
+		Name:       "marathon",
+		Thresholds: t,
+		Weights:    w,
+		Exclude:    []string{"RISKY_HEAVY_RAIN"},
+	}
+}
+
+// Outdoor ceremonies are spoiled by any rain.
+func weddingProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.RiskyRainMM, t.UnsafeRainMM = 1, 7.5
+	t.RiskyRainProb = 30
+
+	w := cloneWeights(base.Weights)
+	w.RainMM, w.RainProb = 0.3, 0.4
+
+	return Profile{Name: "wedding", Thresholds: t, Weights: w}
+}
+
+// Matches are played in rain and wind, but not in lightning.
+func footballProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.RiskyRainMM, t.UnsafeRainMM = 5, 15
+	t.RiskyRainProb = 90
+	t.RiskyWindKmh, t.UnsafeWindKmh = 40, 55
+	t.RiskyGustKmh, t.UnsafeGustKmh = 55, 75
+
+	w := cloneWeights(base.Weights)
+	w.RainMM, w.RainProb, w.Wind = 0.1, 0.1, 0.3
+	w.Convective = 0.4
+
+	return Profile{
+		Name:       "football",
+		Thresholds: t,
+		Weights:    w,
+		Exclude:    []string{"RISKY_HEAVY_RAIN"},
+	}
+}
+
+// Shells drift in the wind, and the show is lost in fog or low clouds.
+func fireworksProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.RiskyWindKmh, t.UnsafeWindKmh = 20, 32
+	t.RiskyGustKmh, t.UnsafeGustKmh = 35, 50
+	t.RiskyVisibilityM, t.UnsafeVisibilityM = 3000, 1000
+	t.RiskyRainMM = 1
+
+	w := cloneWeights(base.Weights)
+	w.Wind, w.Gust, w.Visibility = 0.6, 0.4, 0.5
+
+	return Profile{Name: "fireworks", Thresholds: t, Weights: w}
+}
+
+// Stalls and gazebos blow over in gusts, while fog does not matter.
+func marketProfile(base Profile) Profile {
+	t := base.Thresholds
+	t.RiskyGustKmh, t.UnsafeGustKmh = 40, 55
+	t.RiskyRainMM = 4
+
+	w := cloneWeights(base.Weights)
+	w.Gust = 0.5
+
+	return Profile{
+		Name:       "market",
+		Thresholds: t,
+		Weights:    w,
+		Exclude:    []string{"UNSAFE_DENSE_FOG", "RISKY_LOW_VISIBILITY", "RISKY_FOG"},
+	}
+}
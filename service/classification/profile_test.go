@@ -0,0 +1,65 @@
+package classification
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+func TestWithOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds string
+		weights    string
+		wantErr    string
+	}{
+		{name: "none"},
+		{name: "thresholds", thresholds: `{"risky_wind_kmh":20,"unsafe_wind_kmh":30}`},
+		{name: "weights", weights: `{"wind":0.5,"storm":{"thunderstorm":2}}`},
+		{name: "unknown field", thresholds: `{"risky_wind":20}`, wantErr: `unknown field "risky_wind"`},
+		{name: "trailing object", thresholds: `{"risky_wind_kmh":20} {"x":1}`, wantErr: "unexpected data after the JSON object"},
+		{name: "trailing brace", weights: `{"wind":0.5}}`, wantErr: "unexpected data after the JSON object"},
+		{name: "invalid thresholds", thresholds: `{"risky_wind_kmh":50}`, wantErr: "risky_wind_kmh (50) must be below unsafe_wind_kmh (40)"},
+		{name: "invalid weights", weights: `{"storm":{"drizzle_storm":1}}`, wantErr: `unknown weather category "drizzle_storm"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := DefaultProfile.WithOverrides(json.RawMessage(tt.thresholds), json.RawMessage(tt.weights))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WithOverrides: %v", err)
+			}
+
+			// Fields left out keep the values of the profile
+			if tt.thresholds != "" && (p.Thresholds.RiskyWindKmh != 20 || p.Thresholds.UnsafeRainMM != DefaultThresholds.UnsafeRainMM) {
+				t.Errorf("thresholds = %+v", p.Thresholds)
+			}
+			if tt.weights != "" && (p.Weights.Wind != 0.5 || p.Weights.Gust != DefaultWeights.Gust) {
+				t.Errorf("weights = %+v", p.Weights)
+			}
+		})
+	}
+}
+
+func TestWithOverridesCopiesStormWeights(t *testing.T) {
+	before := DefaultProfile.Weights.Storm[model.CategoryThunderstorm]
+
+	p, err := DefaultProfile.WithOverrides(nil, json.RawMessage(`{"storm":{"thunderstorm":9}}`))
+	if err != nil {
+		t.Fatalf("WithOverrides: %v", err)
+	}
+	if p.Weights.Storm[model.CategoryThunderstorm] != 9 {
+		t.Errorf("storm weights = %v, want the override", p.Weights.Storm)
+	}
+	if got := DefaultProfile.Weights.Storm[model.CategoryThunderstorm]; got != before {
+		t.Errorf("default storm weight changed to %v", got)
+	}
+}
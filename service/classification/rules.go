@@ -1,72 +1,184 @@
 package classification
 
 import (
-	"fmt"
-
 	"github.com/ihgazi/EventWeatherGuard/model"
 )
 
 // Rule defines the criteria for classifying weather severity levels
 //
 // Each rule specifies thresholds and conditions for weather parameters (e.g., precipitation,
-// wind speed) and is associated with specific event types.
+// wind speed) and is associated with specific event types. Rules are compiled from a RuleDef.
 type RiskRule struct {
-	ID          string
-	Level       RiskLevel
+	ID    string
+	Level RiskLevel
+	// Venue restricts the rule to events at the given venue type. Rules without
+	// a venue apply to every event.
+	Venue       model.VenueType
 	Matches     func(h model.HourlyForecast, t SeverityThresholds) bool
-	Description func(h model.HourlyForecast) string
+	Description func(h model.HourlyForecast, t SeverityThresholds) string
 }
 
-var ClassificationRules = []RiskRule{
+// BuiltinRules declares the built-in rules, in evaluation order.
+var BuiltinRules = []RuleDef{
 	{
-		ID:    "UNSAFE_THUNDERSTORM",
-		Level: Unsafe,
-		Matches: func(h model.HourlyForecast, t SeverityThresholds) bool {
-			return h.Weather == "Thunderstorm"
-		},
-		Description: func(h model.HourlyForecast) string {
-			return fmt.Sprintf("Thunderstorm predicted at %s", h.Time.Format("15:04"))
-		},
+		ID:          "UNSAFE_THUNDERSTORM",
+		Level:       Unsafe,
+		When:        `category == "thunderstorm"`,
+		Description: `{{.weather}} predicted at {{.time}}`,
 	},
 	{
 		ID:    "UNSAFE_EXTREME_RAIN_WIND",
 		Level: Unsafe,
-		Matches: func(h model.HourlyForecast, t SeverityThresholds) bool {
-			return h.Precipitation >= t.UnsafeRainMM || h.WindKmh >= t.UnsafeWindKmh
-		},
-		Description: func(h model.HourlyForecast) string {
-			return fmt.Sprintf(
-				"Extreme weather: %.1f mm rain and %.1f km/h wind at %s",
-				h.Precipitation,
-				h.WindKmh,
-				h.Time.Format("15:04"),
-			)
-		},
+		When:  `precip_mm >= unsafe_rain_mm || wind_kmh >= unsafe_wind_kmh || gust_kmh >= unsafe_gust_kmh`,
+		Description: `Extreme weather: {{printf "%.1f" .precip_mm}} mm rain and {{printf "%.1f" .wind_kmh}} km/h wind ` +
+			`(gusts {{printf "%.1f" .gust_kmh}} km/h) at {{.time}}`,
 	},
 	{
 		ID:    "RISKY_MODERATE_RAIN_WIND",
 		Level: Risky,
-		Matches: func(h model.HourlyForecast, t SeverityThresholds) bool {
-			return h.Precipitation >= t.RiskyRainMM || h.WindKmh >= t.RiskyWindKmh || h.RainProb >= t.RiskyRainProb
-		},
-		Description: func(h model.HourlyForecast) string {
-			return fmt.Sprintf(
-				"Moderate risk: %.1f mm rain, %.1f km/h wind, %d%% rain probability at %s",
-				h.Precipitation,
-				h.WindKmh,
-				h.RainProb,
-				h.Time.Format("15:04"),
-			)
-		},
-	},
-	{
-		ID:    "RISKY_HEAVY_RAIN",
+		When: `precip_mm >= risky_rain_mm || wind_kmh >= risky_wind_kmh || gust_kmh >= risky_gust_kmh ||
+			rain_prob >= risky_rain_prob`,
+		Description: `Moderate risk: {{printf "%.1f" .precip_mm}} mm rain, {{printf "%.1f" .wind_kmh}} km/h wind ` +
+			`(gusts {{printf "%.1f" .gust_kmh}} km/h), {{printf "%.0f" .rain_prob}}% rain probability at {{.time}}`,
+	},
+	{
+		ID:          "RISKY_HEAVY_RAIN",
+		Level:       Risky,
+		When:        `category == "heavy_rain"`,
+		Description: `{{.weather}} predicted at {{.time}}`,
+	},
+	{
+		ID:    "UNSAFE_HEAT_DANGER",
+		Level: Unsafe,
+		When:  `heat_index_c >= heat_danger_c`,
+		Description: `Heat danger: heat index {{printf "%.1f" .heat_index_c}}°C ` +
+			`({{printf "%.1f" .temp_c}}°C, {{printf "%.0f" .humidity_pct}}% humidity) at {{.time}}`,
+	},
+	{
+		ID:    "RISKY_HEAT_EXTREME_CAUTION",
+		Level: Risky,
+		When:  `heat_index_c >= heat_extreme_caution_c`,
+		Description: `Heat extreme caution: heat index {{printf "%.1f" .heat_index_c}}°C ` +
+			`({{printf "%.1f" .temp_c}}°C, {{printf "%.0f" .humidity_pct}}% humidity) at {{.time}}`,
+	},
+	{
+		// The caution band only calls for precautions, up to extreme caution
+		ID:    "ADVISORY_HEAT_CAUTION",
+		Level: Advisory,
+		When:  `heat_index_c >= heat_caution_c && heat_index_c < heat_extreme_caution_c`,
+		Description: `Heat caution: heat index {{printf "%.1f" .heat_index_c}}°C ` +
+			`({{printf "%.1f" .temp_c}}°C, {{printf "%.0f" .humidity_pct}}% humidity) at {{.time}}: ` +
+			`provide shade and water stations`,
+	},
+	{
+		ID:    "UNSAFE_EXTREME_COLD",
+		Level: Unsafe,
+		When:  `wind_chill_c <= unsafe_wind_chill_c`,
+		Description: `Extreme cold: wind chill {{printf "%.1f" .wind_chill_c}}°C ` +
+			`({{printf "%.1f" .temp_c}}°C, {{printf "%.1f" .wind_kmh}} km/h wind) at {{.time}}`,
+	},
+	{
+		ID:    "RISKY_WIND_CHILL",
+		Level: Risky,
+		When:  `wind_chill_c <= risky_wind_chill_c`,
+		Description: `Cold stress: wind chill {{printf "%.1f" .wind_chill_c}}°C ` +
+			`({{printf "%.1f" .temp_c}}°C, {{printf "%.1f" .wind_kmh}} km/h wind) at {{.time}}`,
+	},
+	{
+		ID:          "UNSAFE_HEAVY_SNOWFALL",
+		Level:       Unsafe,
+		When:        `snowfall_cm >= unsafe_snowfall_cm`,
+		Description: `Heavy snowfall: {{printf "%.1f" .snowfall_cm}} cm/h at {{.time}}`,
+	},
+	{
+		ID:          "RISKY_SNOWFALL",
+		Level:       Risky,
+		When:        `snowfall_cm >= risky_snowfall_cm`,
+		Description: `Snowfall: {{printf "%.1f" .snowfall_cm}} cm/h ({{printf "%.0f" .snow_depth_cm}} cm on the ground) at {{.time}}`,
+	},
+	{
+		ID:          "UNSAFE_ICING",
+		Level:       Unsafe,
+		When:        `category == "freezing" && precip_mm >= unsafe_freezing_rain_mm`,
+		Description: `Icing: {{lower .weather}} with {{printf "%.1f" .precip_mm}} mm precipitation at {{.time}}`,
+	},
+	{
+		ID:          "RISKY_FREEZING_PRECIPITATION",
+		Level:       Risky,
+		When:        `category == "freezing"`,
+		Description: `{{.weather}} predicted at {{.time}}`,
+	},
+	{
+		ID:          "UNSAFE_DENSE_FOG",
+		Level:       Unsafe,
+		When:        `visibility_m > 0 && visibility_m < unsafe_visibility_m`,
+		Description: `Dense fog: visibility {{printf "%.0f" .visibility_m}} m at {{.time}}`,
+	},
+	{
+		ID:          "RISKY_LOW_VISIBILITY",
+		Level:       Risky,
+		When:        `visibility_m > 0 && visibility_m < risky_visibility_m`,
+		Description: `Low visibility: {{printf "%.0f" .visibility_m}} m at {{.time}}`,
+	},
+	{
+		// Fog reported by the weather code, for providers without a visibility forecast
+		ID:          "RISKY_FOG",
+		Level:       Risky,
+		When:        `category == "fog"`,
+		Description: `{{.weather}} predicted at {{.time}}`,
+	},
+	{
+		ID:    "RISKY_CONVECTIVE",
+		Level: Risky,
+		When:  `cape_jkg >= risky_cape_jkg || lifted_index <= risky_lifted_index_c`,
+		Description: `Convective risk: possible thunderstorms and lightning ` +
+			`(CAPE {{printf "%.0f" .cape_jkg}} J/kg, lifted index {{printf "%.1f" .lifted_index}}) at {{.time}}`,
+	},
+	{
+		ID:          "ADVISORY_UV",
+		Level:       Advisory,
+		When:        `uv_index >= advisory_uv_index`,
+		Description: `High UV index {{printf "%.1f" .uv_index}} at {{.time}}: provide shade, sunscreen and water stations`,
+	},
+	{
+		ID:    "UNSAFE_AIR_QUALITY",
+		Level: Unsafe,
+		When:  `has_air_quality && (us_aqi >= unsafe_us_aqi || european_aqi >= unsafe_european_aqi)`,
+		Description: `Hazardous air quality: US AQI {{.us_aqi}}, European AQI {{.european_aqi}} ` +
+			`(PM2.5 {{printf "%.1f" .pm2_5}} μg/m³) at {{.time}}`,
+	},
+	{
+		ID:    "RISKY_AIR_QUALITY",
+		Level: Risky,
+		When:  `has_air_quality && (us_aqi >= risky_us_aqi || european_aqi >= risky_european_aqi)`,
+		Description: `Unhealthy air quality: US AQI {{.us_aqi}}, European AQI {{.european_aqi}} ` +
+			`(PM2.5 {{printf "%.1f" .pm2_5}} μg/m³) at {{.time}}`,
+	},
+	{
+		// Hours with risky air quality are already reported by the rules above
+		ID:    "ADVISORY_AIR_QUALITY",
+		Level: Advisory,
+		When: `has_air_quality && (us_aqi >= advisory_us_aqi || european_aqi >= advisory_european_aqi) &&
+			us_aqi < risky_us_aqi && european_aqi < risky_european_aqi`,
+		Description: `Air quality unhealthy for sensitive groups (US AQI {{.us_aqi}}, European AQI {{.european_aqi}}) ` +
+			`at {{.time}}: advise attendees with respiratory conditions`,
+	},
+	{
+		ID:          "UNSAFE_HIGH_WAVES",
+		Level:       Unsafe,
+		Venue:       model.VenueWater,
+		When:        `has_marine && wave_height_m >= unsafe_wave_height_m`,
+		Description: `High waves: {{printf "%.1f" .wave_height_m}} m every {{printf "%.0f" .wave_period_s}} s at {{.time}}`,
+	},
+	{
+		ID:    "RISKY_ROUGH_SEA",
 		Level: Risky,
-		Matches: func(h model.HourlyForecast, t SeverityThresholds) bool {
-			return h.Weather == "Heavy Rain"
-		},
-		Description: func(h model.HourlyForecast) string {
-			return fmt.Sprintf("Heavy rain predicted at %s", h.Time.Format("15:04"))
-		},
+		Venue: model.VenueWater,
+		When:  `has_marine && (wave_height_m >= risky_wave_height_m || swell_height_m >= risky_swell_height_m)`,
+		Description: `Rough sea: {{printf "%.1f" .wave_height_m}} m waves and {{printf "%.1f" .swell_height_m}} m swell ` +
+			`({{printf "%.0f" .swell_period_s}} s period) at {{.time}}`,
 	},
 }
+
+// ClassificationRules are the compiled built-in rules.
+var ClassificationRules = mustCompileRules(BuiltinRules)
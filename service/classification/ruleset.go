@@ -0,0 +1,227 @@
+package classification
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// BuiltinVersion is the version of the rule set compiled into the service.
+const BuiltinVersion = "builtin"
+
+// RuleSet is a versioned set of classification rules and profiles.
+type RuleSet struct {
+	Version string
+	// Config is the config the rule set was built from.
+	Config RuleSetConfig
+	// Rules lists the rules in evaluation order.
+	Rules []RiskRule
+	// Default is the profile of events without an event type.
+	Default Profile
+	// Profiles lists the event-type profiles, keyed by event type.
+	Profiles map[string]Profile
+}
+
+// Criteria selects the rules, profile and venue an event is classified with.
+type Criteria struct {
+	Rules   []RiskRule
+	Profile Profile
+	Venue   model.VenueType
+}
+
+// BuiltinRuleSet returns the rule set compiled into the service.
+func BuiltinRuleSet() *RuleSet {
+	return &RuleSet{
+		Version:  BuiltinVersion,
+		Config:   RuleSetConfig{Version: BuiltinVersion},
+		Rules:    ClassificationRules,
+		Default:  DefaultProfile,
+		Profiles: Profiles,
+	}
+}
+
+// Lookup returns the profile of an event type. An empty event type selects
+// the default profile.
+func (rs *RuleSet) Lookup(eventType string) (Profile, bool) {
+	if eventType == "" {
+		return rs.Default, true
+	}
+
+	p, ok := rs.Profiles[eventType]
+	return p, ok
+}
+
+// EventTypes returns the event types with a profile, sorted by name.
+func (rs *RuleSet) EventTypes() []string {
+	return slices.Sorted(maps.Keys(rs.Profiles))
+}
+
+// RuleSetConfig is the schema of a rule set config.
+type RuleSetConfig struct {
+	// Version identifies the config, and is reported with every classification.
+	Version string `json:"version"`
+	// Thresholds and Weights are merged over the built-in defaults.
+	Thresholds json.RawMessage `json:"thresholds,omitempty" swaggertype:"object"`
+	Weights    json.RawMessage `json:"weights,omitempty" swaggertype:"object"`
+	// Rules lists the enabled rules in evaluation order. Fields set on a
+	// built-in rule replace its own, and new rules declare every field. Every
+	// built-in rule is enabled when left out.
+	Rules []RuleDef `json:"rules"`
+	// Profiles replaces the built-in event-type profiles when present. The
+	// built-in profiles otherwise adjust the thresholds and weights of the config.
+	Profiles map[string]ProfileConfig `json:"profiles"`
+}
+
+// ProfileConfig is an event-type profile. Its thresholds and weights are
+// merged over those of the rule set.
+type ProfileConfig struct {
+	Thresholds json.RawMessage `json:"thresholds,omitempty" swaggertype:"object"`
+	Weights    json.RawMessage `json:"weights,omitempty" swaggertype:"object"`
+	// Rules lists the IDs of the rules enabled for the event type. Every rule
+	// of the rule set is enabled when left out.
+	Rules []string `json:"rules"`
+	// Exclude lists the IDs of the rules disabled for the event type. Built-in
+	// rules may be listed even when the rule set leaves them out.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// ParseRuleSet parses and validates a rule set config in the given format
+// ("yaml", "yml" or "json").
+func ParseRuleSet(data []byte, format string) (*RuleSet, error) {
+	cfg, err := DecodeRuleSetConfig(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Build()
+}
+
+// DecodeRuleSetConfig decodes a rule set config in the given format ("yaml",
+// "yml" or "json"), without validating it. Unknown fields are rejected.
+func DecodeRuleSetConfig(data []byte, format string) (RuleSetConfig, error) {
+	switch format {
+	case "json":
+	case "yaml", "yml":
+		// YAML is converted to JSON so that both formats share the same schema
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return RuleSetConfig{}, err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return RuleSetConfig{}, err
+		}
+	default:
+		return RuleSetConfig{}, fmt.Errorf("unsupported rule set format %q", format)
+	}
+
+	var cfg RuleSetConfig
+	if err := decodeStrict(data, &cfg); err != nil {
+		return RuleSetConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+// Build validates the config and resolves it into a rule set.
+func (f RuleSetConfig) Build() (*RuleSet, error) {
+	if strings.TrimSpace(f.Version) == "" {
+		return nil, errors.New("version is required")
+	}
+
+	base, err := DefaultProfile.WithOverrides(f.Thresholds, f.Weights)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := f.buildRules()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &RuleSet{
+		Version: f.Version,
+		Config:  f,
+		Rules:   rules,
+		Default: base,
+	}
+
+	if f.Profiles == nil {
+		rs.Profiles = builtinProfiles(base)
+		for name, p := range rs.Profiles {
+			if err := p.validate(); err != nil {
+				return nil, fmt.Errorf("built-in profile %s: %w", name, err)
+			}
+		}
+		return rs, nil
+	}
+
+	rs.Profiles = make(map[string]Profile, len(f.Profiles))
+
+	for name, pc := range f.Profiles {
+		p, err := base.WithOverrides(pc.Thresholds, pc.Weights)
+		if err != nil {
+			return nil, fmt.Errorf("profiles.%s: %w", name, err)
+		}
+
+		for _, id := range pc.Rules {
+			if !slices.ContainsFunc(rules, func(r RiskRule) bool { return r.ID == id }) {
+				return nil, fmt.Errorf("profiles.%s: unknown rule %q", name, id)
+			}
+		}
+		for _, id := range pc.Exclude {
+			if !slices.ContainsFunc(rules, func(r RiskRule) bool { return r.ID == id }) &&
+				!slices.ContainsFunc(BuiltinRules, func(d RuleDef) bool { return d.ID == id }) {
+				return nil, fmt.Errorf("profiles.%s: unknown rule %q", name, id)
+			}
+		}
+
+		p.Name, p.Rules, p.Exclude = name, pc.Rules, pc.Exclude
+		rs.Profiles[name] = p
+	}
+
+	return rs, nil
+}
+
+// buildRules compiles the enabled rules, completing the built-in ones.
+func (f RuleSetConfig) buildRules() ([]RiskRule, error) {
+	if f.Rules == nil {
+		return ClassificationRules, nil
+	}
+
+	rules := make([]RiskRule, 0, len(f.Rules))
+
+	for i, rc := range f.Rules {
+		if slices.ContainsFunc(rules, func(r RiskRule) bool { return r.ID == rc.ID }) {
+			return nil, fmt.Errorf("rules[%d]: duplicate rule %q", i, rc.ID)
+		}
+
+		def := rc
+		if idx := slices.IndexFunc(BuiltinRules, func(d RuleDef) bool { return d.ID == rc.ID }); idx >= 0 {
+			def = BuiltinRules[idx]
+			def.Level = cmp.Or(rc.Level, def.Level)
+			def.Venue = cmp.Or(rc.Venue, def.Venue)
+			def.When = cmp.Or(rc.When, def.When)
+			def.Description = cmp.Or(rc.Description, def.Description)
+		} else if rc.When == "" {
+			return nil, fmt.Errorf("rules[%d]: unknown rule %q: new rules require a condition (when)", i, rc.ID)
+		}
+
+		rule, err := CompileRule(def)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d] (%s): %w", i, rc.ID, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
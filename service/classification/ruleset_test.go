@@ -0,0 +1,78 @@
+package classification
+
+import (
+	"testing"
+)
+
+// omitsHeavyRain is a config leaving out a built-in rule and adding a new one,
+// without profiles.
+const omitsHeavyRain = `
+version: "2026-10"
+thresholds:
+  unsafe_wave_height_m: 3
+  risky_wave_height_m: 1.5
+weights:
+  waves: 0.9
+rules:
+  - id: UNSAFE_THUNDERSTORM
+  - id: RISKY_FOG
+  - id: RISKY_HAIL
+    level: Risky
+    when: weather_code == 99
+    description: Hail is expected
+`
+
+func TestBuildBuiltinProfilesFollowConfig(t *testing.T) {
+	rs, err := ParseRuleSet([]byte(omitsHeavyRain), "yaml")
+	if err != nil {
+		t.Fatalf("ParseRuleSet: %v", err)
+	}
+
+	for name, p := range rs.Profiles {
+		if p.Thresholds.UnsafeWaveHeightM != 3 || p.Thresholds.RiskyWaveHeightM != 1.5 {
+			t.Errorf("%s: wave height thresholds %v/%v, want those of the config",
+				name, p.Thresholds.RiskyWaveHeightM, p.Thresholds.UnsafeWaveHeightM)
+		}
+		if p.Weights.Waves != 0.9 {
+			t.Errorf("%s: waves weight %v, want that of the config", name, p.Weights.Waves)
+		}
+		if !p.Enabled("RISKY_HAIL") {
+			t.Errorf("%s: rule added by the config is disabled", name)
+		}
+	}
+
+	// The adjustments of the profiles still apply
+	if got := rs.Profiles["concert"].Thresholds.UnsafeWindKmh; got != 35 {
+		t.Errorf("concert: unsafe_wind_kmh = %v, want 35", got)
+	}
+	if rs.Profiles["market"].Enabled("RISKY_FOG") {
+		t.Error("market: fog rule enabled")
+	}
+}
+
+func TestBuildProfileExcludesOmittedRule(t *testing.T) {
+	cfg, err := DecodeRuleSetConfig([]byte(omitsHeavyRain), "yaml")
+	if err != nil {
+		t.Fatalf("DecodeRuleSetConfig: %v", err)
+	}
+
+	rs, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	// Listing the built-in profiles in the config, as the admin API does when
+	// a profile is updated, keeps the exclusion of the omitted heavy rain rule
+	cfg.Profiles = make(map[string]ProfileConfig, len(rs.Profiles))
+	for name, p := range rs.Profiles {
+		cfg.Profiles[name] = p.Config()
+	}
+	if _, err := cfg.Build(); err != nil {
+		t.Fatalf("Build with the built-in profiles listed: %v", err)
+	}
+
+	cfg.Profiles["market"] = ProfileConfig{Exclude: []string{"RISKY_SLEET"}}
+	if _, err := cfg.Build(); err == nil {
+		t.Error("expected an error for an unknown excluded rule")
+	}
+}
@@ -0,0 +1,111 @@
+package classification
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Validate checks that the thresholds are consistent: each risky limit must be
+// less severe than the matching unsafe limit, the limits used to normalize
+// severity scores must be positive, and the other limits within their range.
+func (t SeverityThresholds) Validate() error {
+	checks := []error{
+		positive("unsafe_rain_mm", t.UnsafeRainMM),
+		positive("unsafe_wind_kmh", t.UnsafeWindKmh),
+		positive("unsafe_gust_kmh", t.UnsafeGustKmh),
+		positive("unsafe_snowfall_cm", t.UnsafeSnowfallCm),
+		positive("unsafe_freezing_rain_mm", t.UnsafeFreezingRainMM),
+		positive("unsafe_visibility_m", t.UnsafeVisibilityM),
+		positive("risky_cape_jkg", t.RiskyCapeJkg),
+		positive("advisory_uv_index", t.AdvisoryUVIndex),
+		positive("unsafe_wave_height_m", t.UnsafeWaveHeightM),
+		positive("risky_swell_height_m", t.RiskySwellHeightM),
+		positive("disagree_rain_mm", t.DisagreeRainMM),
+		positive("disagree_wind_kmh", t.DisagreeWindKmh),
+
+		below("risky_rain_mm", t.RiskyRainMM, "unsafe_rain_mm", t.UnsafeRainMM),
+		below("risky_wind_kmh", t.RiskyWindKmh, "unsafe_wind_kmh", t.UnsafeWindKmh),
+		below("risky_gust_kmh", t.RiskyGustKmh, "unsafe_gust_kmh", t.UnsafeGustKmh),
+		below("heat_caution_c", t.HeatCautionC, "heat_extreme_caution_c", t.HeatExtremeCautionC),
+		below("heat_extreme_caution_c", t.HeatExtremeCautionC, "heat_danger_c", t.HeatDangerC),
+		below("unsafe_wind_chill_c", t.UnsafeWindChillC, "risky_wind_chill_c", t.RiskyWindChillC),
+		below("risky_snowfall_cm", t.RiskySnowfallCm, "unsafe_snowfall_cm", t.UnsafeSnowfallCm),
+		below("unsafe_visibility_m", t.UnsafeVisibilityM, "risky_visibility_m", t.RiskyVisibilityM),
+		below("advisory_us_aqi", t.AdvisoryUSAQI, "risky_us_aqi", t.RiskyUSAQI),
+		below("risky_us_aqi", t.RiskyUSAQI, "unsafe_us_aqi", t.UnsafeUSAQI),
+		below("advisory_european_aqi", t.AdvisoryEuropeanAQI, "risky_european_aqi", t.RiskyEuropeanAQI),
+		below("risky_european_aqi", t.RiskyEuropeanAQI, "unsafe_european_aqi", t.UnsafeEuropeanAQI),
+		below("risky_wave_height_m", t.RiskyWaveHeightM, "unsafe_wave_height_m", t.UnsafeWaveHeightM),
+	}
+
+	if t.RiskyRainProb < 0 || t.RiskyRainProb > 100 {
+		checks = append(checks, fmt.Errorf("risky_rain_prob (%d) must be between 0 and 100", t.RiskyRainProb))
+	}
+
+	// The lifted index is negative in unstable air, and rarely below -10
+	if t.RiskyLiftedIndexC < -15 || t.RiskyLiftedIndexC >= 0 {
+		checks = append(checks, fmt.Errorf("risky_lifted_index_c (%v) must be between -15 and 0", t.RiskyLiftedIndexC))
+	}
+
+	return firstError(checks)
+}
+
+// Validate checks that the weights are non-negative and only refer to known
+// weather code categories.
+func (w SeverityWeights) Validate() error {
+	checks := []error{
+		nonNegative("rain_mm", w.RainMM),
+		nonNegative("rain_prob", w.RainProb),
+		nonNegative("wind", w.Wind),
+		nonNegative("gust", w.Gust),
+		nonNegative("heat", w.Heat),
+		nonNegative("cold", w.Cold),
+		nonNegative("snow", w.Snow),
+		nonNegative("freezing", w.Freezing),
+		nonNegative("visibility", w.Visibility),
+		nonNegative("convective", w.Convective),
+		nonNegative("air_quality", w.AirQuality),
+		nonNegative("waves", w.Waves),
+	}
+
+	for category, v := range w.Storm {
+		if !category.Valid() {
+			checks = append(checks, fmt.Errorf("unknown weather category %q in storm", category))
+			continue
+		}
+		checks = append(checks, nonNegative("storm."+string(category), v))
+	}
+
+	return firstError(checks)
+}
+
+func positive(name string, v float64) error {
+	if v <= 0 {
+		return fmt.Errorf("%s (%v) must be positive", name, v)
+	}
+	return nil
+}
+
+func nonNegative(name string, v float64) error {
+	if v < 0 {
+		return fmt.Errorf("%s (%v) must not be negative", name, v)
+	}
+	return nil
+}
+
+// below checks that the first limit is strictly below the second one.
+func below[T cmp.Ordered](name string, v T, limitName string, limit T) error {
+	if v >= limit {
+		return fmt.Errorf("%s (%v) must be below %s (%v)", name, v, limitName, limit)
+	}
+	return nil
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package classification
+
+import (
+	"testing"
+)
+
+func TestSeverityThresholdsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*SeverityThresholds)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(*SeverityThresholds) {}},
+		{
+			name:    "risky above unsafe",
+			modify:  func(t *SeverityThresholds) { t.RiskyGustKmh = 70 },
+			wantErr: "risky_gust_kmh (70) must be below unsafe_gust_kmh (60)",
+		},
+		{
+			name:    "inverted heat bands",
+			modify:  func(t *SeverityThresholds) { t.HeatCautionC = 35 },
+			wantErr: "heat_caution_c (35) must be below heat_extreme_caution_c (32)",
+		},
+		{
+			name:    "rain probability",
+			modify:  func(t *SeverityThresholds) { t.RiskyRainProb = 101 },
+			wantErr: "risky_rain_prob (101) must be between 0 and 100",
+		},
+		{
+			name:    "zero swell height",
+			modify:  func(t *SeverityThresholds) { t.RiskySwellHeightM = 0 },
+			wantErr: "risky_swell_height_m (0) must be positive",
+		},
+		{
+			name:    "negative uv index",
+			modify:  func(t *SeverityThresholds) { t.AdvisoryUVIndex = -1 },
+			wantErr: "advisory_uv_index (-1) must be positive",
+		},
+		{
+			name:    "stable lifted index",
+			modify:  func(t *SeverityThresholds) { t.RiskyLiftedIndexC = 2 },
+			wantErr: "risky_lifted_index_c (2) must be between -15 and 0",
+		},
+		{
+			name:    "implausible lifted index",
+			modify:  func(t *SeverityThresholds) { t.RiskyLiftedIndexC = -40 },
+			wantErr: "risky_lifted_index_c (-40) must be between -15 and 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds := DefaultThresholds
+			tt.modify(&thresholds)
+
+			err := thresholds.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeverityWeightsValidate(t *testing.T) {
+	if err := DefaultWeights.Validate(); err != nil {
+		t.Errorf("default weights: %v", err)
+	}
+
+	w := cloneWeights(DefaultWeights)
+	w.Heat = -0.1
+	if err := w.Validate(); err == nil || err.Error() != "heat (-0.1) must not be negative" {
+		t.Errorf("err = %v, want a negative heat weight", err)
+	}
+}
@@ -0,0 +1,117 @@
+package classification
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	"github.com/ihgazi/EventWeatherGuard/service/classification/expr"
+)
+
+// ruleContext is what rule conditions and descriptions are evaluated against.
+type ruleContext struct {
+	h *model.HourlyForecast
+	t *SeverityThresholds
+}
+
+// forecastVars lists the variables describing the hour. Air quality and sea
+// state variables are zero when they are not forecast, which has_air_quality
+// and has_marine tell apart.
+var forecastVars = []expr.Var[ruleContext]{
+	{Name: "time", Type: expr.String, Get: func(c ruleContext) any { return c.h.Time.Format("15:04") }},
+	{Name: "hour", Type: expr.Number, Get: func(c ruleContext) any { return float64(c.h.Time.Hour()) }},
+	{Name: "rain_prob", Type: expr.Number, Get: func(c ruleContext) any { return float64(c.h.RainProb) }},
+	{Name: "precip_mm", Type: expr.Number, Get: func(c ruleContext) any { return c.h.Precipitation }},
+	{Name: "wind_kmh", Type: expr.Number, Get: func(c ruleContext) any { return c.h.WindKmh }},
+	{Name: "gust_kmh", Type: expr.Number, Get: func(c ruleContext) any { return c.h.GustKmh }},
+	{Name: "weather_code", Type: expr.Number, Get: func(c ruleContext) any { return float64(c.h.WeatherCode) }},
+	{Name: "weather", Type: expr.String, Get: func(c ruleContext) any { return c.h.Weather }},
+	{Name: "category", Type: expr.String, Get: func(c ruleContext) any {
+		return string(model.LookupWMO(c.h.WeatherCode).Category)
+	}, Valid: func(s string) bool {
+		return model.WeatherCategory(s).Valid()
+	}},
+	{Name: "temp_c", Type: expr.Number, Get: func(c ruleContext) any { return c.h.TemperatureC }},
+	{Name: "humidity_pct", Type: expr.Number, Get: func(c ruleContext) any { return c.h.HumidityPct }},
+	{Name: "apparent_temp_c", Type: expr.Number, Get: func(c ruleContext) any { return c.h.ApparentTempC }},
+	{Name: "heat_index_c", Type: expr.Number, Get: func(c ruleContext) any {
+		return HeatIndexC(c.h.TemperatureC, c.h.HumidityPct)
+	}},
+	{Name: "wind_chill_c", Type: expr.Number, Get: func(c ruleContext) any {
+		return WindChillC(c.h.TemperatureC, c.h.WindKmh)
+	}},
+	{Name: "snowfall_cm", Type: expr.Number, Get: func(c ruleContext) any { return c.h.SnowfallCm }},
+	{Name: "snow_depth_cm", Type: expr.Number, Get: func(c ruleContext) any { return c.h.SnowDepthCm }},
+	{Name: "visibility_m", Type: expr.Number, Get: func(c ruleContext) any { return c.h.VisibilityM }},
+	{Name: "cape_jkg", Type: expr.Number, Get: func(c ruleContext) any { return c.h.CapeJkg }},
+	{Name: "lifted_index", Type: expr.Number, Get: func(c ruleContext) any { return c.h.LiftedIndexC }},
+	{Name: "uv_index", Type: expr.Number, Get: func(c ruleContext) any { return c.h.UVIndex }},
+
+	{Name: "has_air_quality", Type: expr.Bool, Get: func(c ruleContext) any { return c.h.AirQuality != nil }},
+	{Name: "pm2_5", Type: expr.Number, Get: func(c ruleContext) any { return airQuality(c.h).PM25 }},
+	{Name: "pm10", Type: expr.Number, Get: func(c ruleContext) any { return airQuality(c.h).PM10 }},
+	{Name: "ozone", Type: expr.Number, Get: func(c ruleContext) any { return airQuality(c.h).Ozone }},
+	{Name: "european_aqi", Type: expr.Number, Get: func(c ruleContext) any { return float64(airQuality(c.h).EuropeanAQI) }},
+	{Name: "us_aqi", Type: expr.Number, Get: func(c ruleContext) any { return float64(airQuality(c.h).USAQI) }},
+
+	{Name: "has_marine", Type: expr.Bool, Get: func(c ruleContext) any { return c.h.Marine != nil }},
+	{Name: "wave_height_m", Type: expr.Number, Get: func(c ruleContext) any { return marine(c.h).WaveHeightM }},
+	{Name: "wave_period_s", Type: expr.Number, Get: func(c ruleContext) any { return marine(c.h).WavePeriodS }},
+	{Name: "swell_height_m", Type: expr.Number, Get: func(c ruleContext) any { return marine(c.h).SwellHeightM }},
+	{Name: "swell_period_s", Type: expr.Number, Get: func(c ruleContext) any { return marine(c.h).SwellPeriodS }},
+	{Name: "swell_direction_deg", Type: expr.Number, Get: func(c ruleContext) any { return marine(c.h).SwellDirection }},
+}
+
+// ruleVars lists every variable of rule conditions and descriptions: the
+// forecast variables, and the thresholds under their JSON names.
+var ruleVars = slices.Concat(forecastVars, thresholdVars())
+
+var ruleEnv = expr.NewEnv(ruleVars...)
+
+// thresholdVars declares a variable for each field of SeverityThresholds.
+func thresholdVars() []expr.Var[ruleContext] {
+	var vars []expr.Var[ruleContext]
+
+	typ := reflect.TypeFor[SeverityThresholds]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+
+		vars = append(vars, expr.Var[ruleContext]{
+			Name: name,
+			Type: expr.Number,
+			Get: func(c ruleContext) any {
+				f := reflect.ValueOf(c.t).Elem().Field(i)
+				if f.CanInt() {
+					return float64(f.Int())
+				}
+				return f.Float()
+			},
+		})
+	}
+
+	return vars
+}
+
+// ruleData returns the variables of the context by name, for descriptions.
+func ruleData(c ruleContext) map[string]any {
+	data := make(map[string]any, len(ruleVars))
+	for _, v := range ruleVars {
+		data[v.Name] = v.Get(c)
+	}
+	return data
+}
+
+func airQuality(h *model.HourlyForecast) model.AirQuality {
+	if h.AirQuality == nil {
+		return model.AirQuality{}
+	}
+	return *h.AirQuality
+}
+
+func marine(h *model.HourlyForecast) model.Marine {
+	if h.Marine == nil {
+		return model.Marine{}
+	}
+	return *h.Marine
+}
@@ -6,37 +6,52 @@ import (
 )
 
 // ClassificationResult represents the outcome of classifying an event's weather risk.
-// It includes the overall risk level, reasons for the classification, a summary message,
-// and a severity score (0-100).
+// It includes the overall risk level, reasons for the classification, advisories,
+// a summary message, a severity score (0-100), and the confidence given how much
+// the weather providers agree.
 type ClassificationResult struct {
 	Classification cls.RiskLevel
 	Reason         []string
+	Advisories     []string
 	Summary        string
 	Severity       int
+	Confidence     cls.Confidence
 }
 
 // ClassifyEvent aggregates hourly weather risk evaluations for an event window
 // and determines the overall event risk level, reasons, summary, and severity.
-func ClassifyEvent(hours []model.HourlyForecast) ClassificationResult {
+// The criteria provide the rules of the active rule set, the profile of the event
+// type with its thresholds and weights, and the venue type, which selects the
+// venue-specific rules such as the marine rules.
+func ClassifyEvent(hours []model.HourlyForecast, criteria cls.Criteria) ClassificationResult {
 	finalLevel := cls.Safe
-	var reasons []string
+	var reasons, advisories []string
 	maxSeverity := 0.0
 	var peakReport cls.HourlyEvaluation
+	confidence := cls.HighConfidence
 
 	// Generate hourly evaluations and find the worst case
 	// Reasons are aggregated over all hourly windows
 	for _, h := range hours {
-		eval := cls.EvaluateHourlyRisk(h, cls.DefaultThresholds, cls.DefaultWeights)
+		eval := cls.EvaluateHourlyRisk(h, criteria)
 
 		if eval.Level == cls.Unsafe {
 			finalLevel = cls.Unsafe
 		} else if eval.Level == cls.Risky && finalLevel != cls.Unsafe {
 			finalLevel = cls.Risky
+		} else if eval.Level == cls.Advisory && finalLevel == cls.Safe {
+			finalLevel = cls.Advisory
 		}
 
-		if eval.Level != cls.Safe {
+		if eval.Level == cls.Risky || eval.Level == cls.Unsafe {
 			reasons = append(reasons, eval.Reason)
 		}
+		advisories = append(advisories, eval.Advisories...)
+
+		// A single hour of strong disagreement lowers the confidence of the whole event
+		if cls.EvaluateConfidence(h, criteria.Profile.Thresholds) == cls.LowConfidence {
+			confidence = cls.LowConfidence
+		}
 
 		if eval.Severity > maxSeverity {
 			maxSeverity = eval.Severity
@@ -51,8 +66,10 @@ func ClassifyEvent(hours []model.HourlyForecast) ClassificationResult {
 	return ClassificationResult{
 		Classification: finalLevel,
 		Reason:         reasons,
+		Advisories:     advisories,
 		Summary:        buildSummary(peakReport),
 		Severity:       int(maxSeverity * 100),
+		Confidence:     confidence,
 	}
 }
 
@@ -61,6 +78,8 @@ func buildSummary(peakReport cls.HourlyEvaluation) string {
 	switch peakReport.Level {
 	case cls.Safe:
 		return "Weather conditions are safe throughout the event."
+	case cls.Advisory:
+		return "Weather conditions are safe, with precautions advised for attendees."
 	case cls.Unsafe:
 		return "Severe weather conditions are expected during the event."
 	default:
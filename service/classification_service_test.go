@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+)
+
+// A calm 15 km/h average with 60 km/h gusts is Unsafe, also when merged with
+// a provider that does not forecast gusts.
+func TestClassifyEventGusts(t *testing.T) {
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	gusty := hourlySeries(start, 3, func(i int, h *model.HourlyForecast) {
+		h.WindKmh, h.GustKmh, h.TemperatureC = 15, 60, 20
+	})
+	noGusts := hourlySeries(start, 3, func(i int, h *model.HourlyForecast) {
+		h.WindKmh, h.TemperatureC = 15, 20
+		h.Missing = []string{"rain_prob", "gust_kmh", "apparent_temp_c"}
+	})
+
+	services := map[string]*WeatherService{
+		"single provider": NewWeatherService(&fakeProvider{name: "open-meteo", hours: gusty}),
+		"consensus": NewConsensusWeatherService(DefaultMergeStrategy,
+			&fakeProvider{name: "open-meteo", hours: gusty},
+			&fakeProvider{name: "met-norway", hours: noGusts},
+		),
+	}
+
+	rs := cls.BuiltinRuleSet()
+	criteria := cls.Criteria{Rules: rs.Rules, Profile: rs.Default, Venue: model.VenueLand}
+
+	for name, svc := range services {
+		t.Run(name, func(t *testing.T) {
+			hours, err := svc.GetEventForecast(context.Background(), 52.52, 13.41, start, start.Add(3*time.Hour), model.VenueLand)
+			if err != nil {
+				t.Fatalf("GetEventForecast: %v", err)
+			}
+
+			result := ClassifyEvent(hours, criteria)
+			if result.Classification != cls.Unsafe {
+				t.Errorf("classification = %s, want %s (reasons %v)", result.Classification, cls.Unsafe, result.Reason)
+			}
+		})
+	}
+}
+
+// The heat caution band is an advisory, while extreme caution and danger make
+// an event Risky and Unsafe.
+func TestClassifyEventHeat(t *testing.T) {
+	start := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		tempC, humidity float64
+		want            cls.RiskLevel
+	}{
+		{tempC: 24, humidity: 40, want: cls.Safe},
+		// Heat index about 28°C
+		{tempC: 28, humidity: 40, want: cls.Advisory},
+		// Heat index about 35°C and 47°C
+		{tempC: 32, humidity: 50, want: cls.Risky},
+		{tempC: 36, humidity: 60, want: cls.Unsafe},
+	}
+
+	rs := cls.BuiltinRuleSet()
+	criteria := cls.Criteria{Rules: rs.Rules, Profile: rs.Default, Venue: model.VenueLand}
+
+	for _, tt := range tests {
+		hours := hourlySeries(start, 3, func(i int, h *model.HourlyForecast) {
+			h.TemperatureC, h.HumidityPct, h.VisibilityM = tt.tempC, tt.humidity, 20000
+		})
+
+		result := ClassifyEvent(hours, criteria)
+		if result.Classification != tt.want {
+			t.Errorf("%v°C at %v%%: classification = %s, want %s (reasons %v)",
+				tt.tempC, tt.humidity, result.Classification, tt.want, result.Reason)
+		}
+	}
+}
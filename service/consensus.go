@@ -0,0 +1,296 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+// MergeMode selects how the values reported by several providers for the same
+// hour are combined into a single value.
+type MergeMode string
+
+const (
+	MergeMean   MergeMode = "mean"
+	MergeMax    MergeMode = "max"
+	MergeMedian MergeMode = "median"
+)
+
+// MergeStrategy selects a merge mode for each forecast field.
+type MergeStrategy struct {
+	// Default applies to the fields without a specific mode.
+	Default MergeMode
+	// Fields selects the mode of individual fields, keyed by their JSON name.
+	Fields map[string]MergeMode
+}
+
+// DefaultMergeStrategy averages every field.
+var DefaultMergeStrategy = MergeStrategy{Default: MergeMean}
+
+// mode returns the merge mode of a field.
+func (s MergeStrategy) mode(field string) MergeMode {
+	if m, ok := s.Fields[field]; ok {
+		return m
+	}
+	return s.Default
+}
+
+// mergeField is a numeric forecast field merged across providers.
+type mergeField struct {
+	name string
+	get  func(model.HourlyForecast) float64
+	set  func(*model.HourlyForecast, float64)
+}
+
+// mergeFields lists the numeric forecast fields, keyed by their JSON name.
+var mergeFields = []mergeField{
+	{
+		name: "rain_prob",
+		get:  func(h model.HourlyForecast) float64 { return float64(h.RainProb) },
+		set:  func(h *model.HourlyForecast, v float64) { h.RainProb = int(math.Round(v)) },
+	},
+	{
+		name: "precip_mm",
+		get:  func(h model.HourlyForecast) float64 { return h.Precipitation },
+		set:  func(h *model.HourlyForecast, v float64) { h.Precipitation = round1(v) },
+	},
+	{
+		name: "wind_kmh",
+		get:  func(h model.HourlyForecast) float64 { return h.WindKmh },
+		set:  func(h *model.HourlyForecast, v float64) { h.WindKmh = round1(v) },
+	},
+	{
+		name: "gust_kmh",
+		get:  func(h model.HourlyForecast) float64 { return h.GustKmh },
+		set:  func(h *model.HourlyForecast, v float64) { h.GustKmh = round1(v) },
+	},
+	{
+		name: "temp_c",
+		get:  func(h model.HourlyForecast) float64 { return h.TemperatureC },
+		set:  func(h *model.HourlyForecast, v float64) { h.TemperatureC = round1(v) },
+	},
+	{
+		name: "humidity_pct",
+		get:  func(h model.HourlyForecast) float64 { return h.HumidityPct },
+		set:  func(h *model.HourlyForecast, v float64) { h.HumidityPct = round1(v) },
+	},
+	{
+		name: "apparent_temp_c",
+		get:  func(h model.HourlyForecast) float64 { return h.ApparentTempC },
+		set:  func(h *model.HourlyForecast, v float64) { h.ApparentTempC = round1(v) },
+	},
+	{
+		name: "snowfall_cm",
+		get:  func(h model.HourlyForecast) float64 { return h.SnowfallCm },
+		set:  func(h *model.HourlyForecast, v float64) { h.SnowfallCm = round1(v) },
+	},
+	{
+		name: "snow_depth_cm",
+		get:  func(h model.HourlyForecast) float64 { return h.SnowDepthCm },
+		set:  func(h *model.HourlyForecast, v float64) { h.SnowDepthCm = round1(v) },
+	},
+	{
+		name: "visibility_m",
+		get:  func(h model.HourlyForecast) float64 { return h.VisibilityM },
+		set:  func(h *model.HourlyForecast, v float64) { h.VisibilityM = math.Round(v) },
+	},
+	{
+		name: "cape_jkg",
+		get:  func(h model.HourlyForecast) float64 { return h.CapeJkg },
+		set:  func(h *model.HourlyForecast, v float64) { h.CapeJkg = math.Round(v) },
+	},
+	{
+		name: "lifted_index",
+		get:  func(h model.HourlyForecast) float64 { return h.LiftedIndexC },
+		set:  func(h *model.HourlyForecast, v float64) { h.LiftedIndexC = round1(v) },
+	},
+	{
+		name: "uv_index",
+		get:  func(h model.HourlyForecast) float64 { return h.UVIndex },
+		set:  func(h *model.HourlyForecast, v float64) { h.UVIndex = round1(v) },
+	},
+}
+
+// ParseMergeStrategy parses a merge strategy specification. The specification
+// is either a single mode applied to every field ("max"), or a comma-separated
+// list of per-field modes ("precip_mm=max,wind_kmh=median,rain_prob=mean").
+// Fields that are not listed keep the default mode.
+func ParseMergeStrategy(spec string) (MergeStrategy, error) {
+	strategy := MergeStrategy{Default: DefaultMergeStrategy.Default}
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return strategy, nil
+	}
+
+	if !strings.Contains(spec, "=") {
+		mode, err := parseMergeMode(spec)
+		if err != nil {
+			return MergeStrategy{}, err
+		}
+		return MergeStrategy{Default: mode}, nil
+	}
+
+	strategy.Fields = make(map[string]MergeMode)
+
+	for _, part := range strings.Split(spec, ",") {
+		field, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return MergeStrategy{}, fmt.Errorf("invalid merge strategy entry %q", part)
+		}
+
+		mode, err := parseMergeMode(value)
+		if err != nil {
+			return MergeStrategy{}, err
+		}
+
+		field = strings.TrimSpace(field)
+		if !slices.ContainsFunc(mergeFields, func(f mergeField) bool { return f.name == field }) {
+			return MergeStrategy{}, fmt.Errorf("unknown merge strategy field %q", field)
+		}
+		strategy.Fields[field] = mode
+	}
+
+	return strategy, nil
+}
+
+func parseMergeMode(s string) (MergeMode, error) {
+	switch mode := MergeMode(strings.TrimSpace(s)); mode {
+	case MergeMean, MergeMax, MergeMedian:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown merge mode %q", s)
+	}
+}
+
+// merge combines the values according to the mode.
+func (m MergeMode) merge(values []float64) float64 {
+	switch m {
+	case MergeMax:
+		return slices.Max(values)
+	case MergeMedian:
+		sorted := slices.Sorted(slices.Values(values))
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2
+		}
+		return sorted[mid]
+	default:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	}
+}
+
+// mergeForecasts merges the hourly series of several providers hour by hour.
+// Fields a provider does not forecast are left out of the merge and of the
+// spread, and remain missing if no provider forecast them. Hours reported by
+// more than one provider carry the spread between them.
+func mergeForecasts(series [][]model.HourlyForecast, strategy MergeStrategy) []model.HourlyForecast {
+	byHour := make(map[time.Time][]model.HourlyForecast)
+	for _, hours := range series {
+		for _, h := range hours {
+			byHour[h.Time] = append(byHour[h.Time], h)
+		}
+	}
+
+	result := make([]model.HourlyForecast, 0, len(byHour))
+
+	for t, hours := range byHour {
+		merged := model.HourlyForecast{
+			Time: t,
+		}
+		worst := worstWeather(hours)
+		merged.WeatherCode, merged.Weather = worst.WeatherCode, worst.Weather
+
+		values := make(map[string][]float64, len(mergeFields))
+		for _, f := range mergeFields {
+			values[f.name] = collect(hours, f)
+			if len(values[f.name]) == 0 {
+				merged.Missing = append(merged.Missing, f.name)
+				continue
+			}
+			f.set(&merged, strategy.mode(f.name).merge(values[f.name]))
+		}
+
+		merged.Extra = mergeExtra(hours, strategy)
+
+		if len(hours) > 1 {
+			merged.Spread = &model.ProviderSpread{
+				Providers:     len(hours),
+				RainProb:      int(spread(values["rain_prob"])),
+				Precipitation: round1(spread(values["precip_mm"])),
+				WindKmh:       round1(spread(values["wind_kmh"])),
+			}
+		}
+
+		result = append(result, merged)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+
+	return result
+}
+
+// mergeExtra merges the extra variables reported by the providers, with the
+// default mode of the strategy unless one is set for the variable.
+func mergeExtra(hours []model.HourlyForecast, strategy MergeStrategy) map[string]float64 {
+	values := make(map[string][]float64)
+	for _, h := range hours {
+		for name, v := range h.Extra {
+			values[name] = append(values[name], v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]float64, len(values))
+	for name, vs := range values {
+		merged[name] = strategy.mode(name).merge(vs)
+	}
+	return merged
+}
+
+// collect returns the values of a field reported by the providers.
+func collect(hours []model.HourlyForecast, f mergeField) []float64 {
+	values := make([]float64, 0, len(hours))
+	for _, h := range hours {
+		if h.Reports(f.name) {
+			values = append(values, f.get(h))
+		}
+	}
+	return values
+}
+
+// spread returns the difference between the highest and lowest value, or zero
+// when there are fewer than two values.
+func spread(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	return slices.Max(values) - slices.Min(values)
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// worstWeather returns the report with the most severe weather code for the hour.
+func worstWeather(hours []model.HourlyForecast) model.HourlyForecast {
+	worst := hours[0]
+	for _, h := range hours[1:] {
+		if model.WeatherCodeRank(h.WeatherCode) > model.WeatherCodeRank(worst.WeatherCode) {
+			worst = h
+		}
+	}
+	return worst
+}
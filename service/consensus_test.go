@@ -0,0 +1,99 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+func TestMergeForecastsMissingFields(t *testing.T) {
+	hour := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	full := model.HourlyForecast{
+		Time:          hour,
+		RainProb:      40,
+		Precipitation: 2,
+		WindKmh:       16,
+		GustKmh:       60,
+		TemperatureC:  20,
+		ApparentTempC: 19,
+		VisibilityM:   8000,
+		Missing:       []string{"uv_index"},
+	}
+	// Like MET Norway, without a precipitation probability, gusts, visibility or UV index
+	partial := model.HourlyForecast{
+		Time:          hour,
+		Precipitation: 4,
+		WindKmh:       14,
+		TemperatureC:  22,
+		Missing:       []string{"rain_prob", "gust_kmh", "apparent_temp_c", "visibility_m", "uv_index"},
+	}
+	for _, mode := range []MergeMode{MergeMean, MergeMedian, MergeMax} {
+		t.Run(string(mode), func(t *testing.T) {
+			merged := mergeForecasts([][]model.HourlyForecast{{full}, {partial}}, MergeStrategy{Default: mode})
+			if len(merged) != 1 {
+				t.Fatalf("got %d hours, want 1", len(merged))
+			}
+			h := merged[0]
+
+			// Fields reported by a single provider keep its value
+			if h.RainProb != 40 || h.GustKmh != 60 || h.ApparentTempC != 19 || h.VisibilityM != 8000 {
+				t.Errorf("rain_prob %d, gust_kmh %v, apparent_temp_c %v, visibility_m %v; want 40, 60, 19, 8000",
+					h.RainProb, h.GustKmh, h.ApparentTempC, h.VisibilityM)
+			}
+
+			if !slices.Equal(h.Missing, []string{"uv_index"}) {
+				t.Errorf("Missing = %v, want [uv_index]", h.Missing)
+			}
+
+			if h.Spread == nil {
+				t.Fatal("Spread is not set")
+			}
+			want := model.ProviderSpread{Providers: 2, RainProb: 0, Precipitation: 2, WindKmh: 2}
+			if *h.Spread != want {
+				t.Errorf("Spread = %+v, want %+v", *h.Spread, want)
+			}
+		})
+	}
+
+	merged := mergeForecasts([][]model.HourlyForecast{{full}, {partial}}, DefaultMergeStrategy)
+	if h := merged[0]; h.Precipitation != 3 || h.WindKmh != 15 || h.TemperatureC != 21 {
+		t.Errorf("precip_mm %v, wind_kmh %v, temp_c %v; want the means 3, 15, 21", h.Precipitation, h.WindKmh, h.TemperatureC)
+	}
+}
+
+func TestMergeForecastsKeepsReportedZeros(t *testing.T) {
+	hour := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Dense fog: zero visibility is a value, not a missing one
+	a := model.HourlyForecast{Time: hour, VisibilityM: 0}
+	b := model.HourlyForecast{Time: hour, VisibilityM: 400}
+
+	merged := mergeForecasts([][]model.HourlyForecast{{a}, {b}}, DefaultMergeStrategy)
+	if got := merged[0].VisibilityM; got != 200 {
+		t.Errorf("visibility_m = %v, want 200", got)
+	}
+}
+
+func TestMergeForecastsExtra(t *testing.T) {
+	hour := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	series := [][]model.HourlyForecast{
+		{{Time: hour, Extra: map[string]float64{"dew_point_2m": 10, "cloud_cover": 80}}},
+		{{Time: hour, Extra: map[string]float64{"dew_point_2m": 12}}},
+		{{Time: hour}},
+	}
+
+	merged := mergeForecasts(series, MergeStrategy{Default: MergeMean})
+	if len(merged) != 1 {
+		t.Fatalf("got %d hours, want 1", len(merged))
+	}
+
+	// Variables are merged over the providers reporting them
+	want := map[string]float64{"dew_point_2m": 11, "cloud_cover": 80}
+	if got := merged[0].Extra; len(got) != len(want) || got["dew_point_2m"] != 11 || got["cloud_cover"] != 80 {
+		t.Errorf("Extra = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+	"github.com/ihgazi/EventWeatherGuard/store"
+)
+
+var (
+	// ErrInvalidRuleSet is returned for rule set configs or profiles that fail validation.
+	ErrInvalidRuleSet = errors.New("invalid rule set")
+	// ErrProfileNotFound is returned for unknown event-type profiles.
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// RuleAdmin manages the revisions of the rule set config kept in a store, and
+// puts them into service on a RuleSource. Every change is recorded with its
+// author and time.
+type RuleAdmin struct {
+	store *store.RuleSetStore
+	rules *RuleSource
+
+	// mu serializes changes, so that profile edits apply to the latest rule set.
+	mu sync.Mutex
+}
+
+func NewRuleAdmin(revisions *store.RuleSetStore, rules *RuleSource) *RuleAdmin {
+	return &RuleAdmin{store: revisions, rules: rules}
+}
+
+// Restore puts the revision activated last back into service, e.g. on startup.
+// It reports whether there was one.
+func (a *RuleAdmin) Restore() (bool, error) {
+	rev, ok, err := a.store.Active()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	rs, err := buildRevision(rev)
+	if err != nil {
+		return false, fmt.Errorf("revision %s: %w", rev.Version, err)
+	}
+
+	a.rules.Set(rs)
+	return true, nil
+}
+
+// Revisions lists the stored revisions and their activations.
+func (a *RuleAdmin) Revisions() (model.RuleSetListResponse, error) {
+	revisions, err := a.store.List()
+	if err != nil {
+		return model.RuleSetListResponse{}, err
+	}
+
+	activations, err := a.store.Activations()
+	if err != nil {
+		return model.RuleSetListResponse{}, err
+	}
+
+	return model.RuleSetListResponse{
+		Active:      a.rules.Current().Version,
+		Revisions:   revisions,
+		Activations: activations,
+	}, nil
+}
+
+// Revision returns the revision with the given version.
+func (a *RuleAdmin) Revision(version string) (model.RuleSetRevision, error) {
+	return a.store.Get(version)
+}
+
+// Create validates and stores a rule set config as a new revision, putting it
+// into service if activate is set.
+func (a *RuleAdmin) Create(author, comment string, cfg cls.RuleSetConfig, activate bool) (model.RuleSetRevision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.create(author, comment, cfg, activate)
+}
+
+func (a *RuleAdmin) create(author, comment string, cfg cls.RuleSetConfig, activate bool) (model.RuleSetRevision, error) {
+	var rs *cls.RuleSet
+
+	rev, err := a.store.Create(author, comment, func(version string) (json.RawMessage, error) {
+		cfg.Version = version
+
+		var err error
+		if rs, err = cfg.Build(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRuleSet, err)
+		}
+
+		return json.Marshal(cfg)
+	})
+	if err != nil {
+		return model.RuleSetRevision{}, err
+	}
+
+	if activate {
+		if _, err := a.store.Activate(rev.Version, author); err != nil {
+			return model.RuleSetRevision{}, err
+		}
+		a.rules.Set(rs)
+	}
+
+	return rev, nil
+}
+
+// Activate puts the revision with the given version into service, rolling
+// back or forward to it.
+func (a *RuleAdmin) Activate(version, author string) (model.RuleSetActivation, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rev, err := a.store.Get(version)
+	if err != nil {
+		return model.RuleSetActivation{}, err
+	}
+
+	// Stored revisions were valid when created, but the built-in rules may have changed since
+	rs, err := buildRevision(rev)
+	if err != nil {
+		return model.RuleSetActivation{}, fmt.Errorf("%w: %w", ErrInvalidRuleSet, err)
+	}
+
+	activation, err := a.store.Activate(version, author)
+	if err != nil {
+		return model.RuleSetActivation{}, err
+	}
+
+	a.rules.Set(rs)
+	return activation, nil
+}
+
+// Profiles returns the event-type profiles of the rule set in service.
+func (a *RuleAdmin) Profiles() map[string]cls.ProfileConfig {
+	rs := a.rules.Current()
+
+	profiles := make(map[string]cls.ProfileConfig, len(rs.Profiles))
+	for name, p := range rs.Profiles {
+		profiles[name] = p.Config()
+	}
+	return profiles
+}
+
+// PutProfile creates or replaces an event-type profile of the rule set in
+// service, as a new revision put into service right away.
+func (a *RuleAdmin) PutProfile(author, name string, profile cls.ProfileConfig) (model.RuleSetRevision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.currentConfig()
+	cfg.Profiles[name] = profile
+
+	return a.create(author, fmt.Sprintf("Update profile %s", name), cfg, true)
+}
+
+// DeleteProfile removes an event-type profile of the rule set in service, as
+// a new revision put into service right away.
+func (a *RuleAdmin) DeleteProfile(author, name string) (model.RuleSetRevision, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.currentConfig()
+	if _, ok := cfg.Profiles[name]; !ok {
+		return model.RuleSetRevision{}, ErrProfileNotFound
+	}
+	delete(cfg.Profiles, name)
+
+	return a.create(author, fmt.Sprintf("Delete profile %s", name), cfg, true)
+}
+
+// currentConfig returns a copy of the config of the rule set in service that
+// can be modified, listing its profiles even when they are the built-in ones.
+func (a *RuleAdmin) currentConfig() cls.RuleSetConfig {
+	rs := a.rules.Current()
+
+	cfg := rs.Config
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]cls.ProfileConfig, len(rs.Profiles))
+		for name, p := range rs.Profiles {
+			cfg.Profiles[name] = p.Config()
+		}
+	} else {
+		cfg.Profiles = maps.Clone(cfg.Profiles)
+	}
+
+	return cfg
+}
+
+// buildRevision validates the config of a stored revision.
+func buildRevision(rev model.RuleSetRevision) (*cls.RuleSet, error) {
+	cfg, err := cls.DecodeRuleSetConfig(rev.Config, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Build()
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+	"github.com/ihgazi/EventWeatherGuard/store"
+)
+
+// openTestStore opens a rule set store at path, closed when the test ends.
+func openTestStore(t *testing.T, path string) *store.RuleSetStore {
+	t.Helper()
+
+	s, err := store.OpenRuleSetStore(path)
+	if err != nil {
+		t.Fatalf("OpenRuleSetStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func newTestAdmin(t *testing.T) (*RuleAdmin, *RuleSource) {
+	t.Helper()
+
+	rules := NewRuleSource()
+	return NewRuleAdmin(openTestStore(t, filepath.Join(t.TempDir(), "rulesets.db")), rules), rules
+}
+
+// invalidConfig enables a rule that does not exist.
+var invalidConfig = cls.RuleSetConfig{Rules: []cls.RuleDef{{ID: "RISKY_HAIL"}}}
+
+func TestRuleAdminCreateAndActivate(t *testing.T) {
+	admin, rules := newTestAdmin(t)
+	builtin := rules.Current().Version
+
+	rev, err := admin.Create("alice", "Draft", cls.RuleSetConfig{}, false)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if rev.Version != "r1" || rev.Author != "alice" || rev.Comment != "Draft" {
+		t.Errorf("revision = %+v", rev)
+	}
+	if got := rules.Current().Version; got != builtin {
+		t.Errorf("version = %q after creating an inactive revision, want %q", got, builtin)
+	}
+
+	if _, err := admin.Create("bob", "Live", cls.RuleSetConfig{}, true); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got := rules.Current().Version; got != "r2" {
+		t.Errorf("version = %q, want the activated revision r2", got)
+	}
+
+	// Roll back to the first revision
+	activation, err := admin.Activate("r1", "carol")
+	if err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if activation.Version != "r1" || activation.Author != "carol" {
+		t.Errorf("activation = %+v", activation)
+	}
+	if got := rules.Current().Version; got != "r1" {
+		t.Errorf("version = %q after rolling back, want r1", got)
+	}
+
+	list, err := admin.Revisions()
+	if err != nil {
+		t.Fatalf("Revisions: %v", err)
+	}
+	if list.Active != "r1" || len(list.Revisions) != 2 || len(list.Activations) != 2 {
+		t.Errorf("list = %+v", list)
+	}
+	if list.Revisions[0].Config != nil {
+		t.Error("listed revisions include their config")
+	}
+
+	stored, err := admin.Revision("r2")
+	if err != nil {
+		t.Fatalf("Revision: %v", err)
+	}
+	var cfg cls.RuleSetConfig
+	if err := json.Unmarshal(stored.Config, &cfg); err != nil || cfg.Version != "r2" {
+		t.Errorf("stored config = %s, want version r2", stored.Config)
+	}
+}
+
+func TestRuleAdminErrors(t *testing.T) {
+	admin, rules := newTestAdmin(t)
+
+	if _, err := admin.Create("alice", "", invalidConfig, true); !errors.Is(err, ErrInvalidRuleSet) {
+		t.Errorf("Create: err = %v, want ErrInvalidRuleSet", err)
+	}
+	if list, err := admin.Revisions(); err != nil || len(list.Revisions) != 0 {
+		t.Errorf("Revisions = %+v, %v, want none stored for an invalid config", list, err)
+	}
+
+	for _, version := range []string{"r1", "1", "latest"} {
+		if _, err := admin.Activate(version, "alice"); !errors.Is(err, store.ErrRevisionNotFound) {
+			t.Errorf("Activate(%q): err = %v, want ErrRevisionNotFound", version, err)
+		}
+	}
+	if _, err := admin.Revision("r1"); !errors.Is(err, store.ErrRevisionNotFound) {
+		t.Errorf("Revision: err = %v, want ErrRevisionNotFound", err)
+	}
+
+	if _, err := admin.DeleteProfile("alice", "regatta"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("DeleteProfile: err = %v, want ErrProfileNotFound", err)
+	}
+	if rules.Current().Version != cls.BuiltinRuleSet().Version {
+		t.Errorf("version = %q after failed changes, want the built-in rules", rules.Current().Version)
+	}
+}
+
+func TestRuleAdminProfiles(t *testing.T) {
+	admin, rules := newTestAdmin(t)
+
+	rev, err := admin.PutProfile("alice", "regatta", cls.ProfileConfig{
+		Thresholds: json.RawMessage(`{"risky_wind_kmh":20,"unsafe_wind_kmh":28}`),
+		Exclude:    []string{"RISKY_FOG"},
+	})
+	if err != nil {
+		t.Fatalf("PutProfile: %v", err)
+	}
+	if rev.Version != "r1" || rev.Comment != "Update profile regatta" {
+		t.Errorf("revision = %+v", rev)
+	}
+
+	rs := rules.Current()
+	if rs.Version != "r1" {
+		t.Errorf("version = %q, want the profile revision put into service", rs.Version)
+	}
+	regatta, ok := rs.Profiles["regatta"]
+	if !ok || regatta.Thresholds.RiskyWindKmh != 20 || regatta.Enabled("RISKY_FOG") {
+		t.Errorf("regatta profile = %+v", regatta)
+	}
+	// The built-in profiles are kept alongside
+	if _, ok := admin.Profiles()["concert"]; !ok {
+		t.Error("built-in concert profile dropped by adding a profile")
+	}
+
+	_, err = admin.PutProfile("alice", "regatta", cls.ProfileConfig{Thresholds: json.RawMessage(`{"risky_wind_kmh":50}`)})
+	if !errors.Is(err, ErrInvalidRuleSet) {
+		t.Errorf("PutProfile: err = %v, want ErrInvalidRuleSet", err)
+	}
+
+	rev, err = admin.DeleteProfile("bob", "regatta")
+	if err != nil {
+		t.Fatalf("DeleteProfile: %v", err)
+	}
+	if rev.Version != "r2" || rev.Author != "bob" || rev.Comment != "Delete profile regatta" {
+		t.Errorf("revision = %+v", rev)
+	}
+	if _, ok := rules.Current().Profiles["regatta"]; ok {
+		t.Error("regatta profile still in service after deleting it")
+	}
+	if _, ok := rules.Current().Profiles["concert"]; !ok {
+		t.Error("built-in concert profile dropped by deleting a profile")
+	}
+
+	// Rolling back restores the profile
+	if _, err := admin.Activate("r1", "bob"); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if _, ok := rules.Current().Profiles["regatta"]; !ok {
+		t.Error("regatta profile missing after rolling back")
+	}
+}
+
+func TestRuleAdminRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rulesets.db")
+
+	revisions, err := store.OpenRuleSetStore(path)
+	if err != nil {
+		t.Fatalf("OpenRuleSetStore: %v", err)
+	}
+
+	admin := NewRuleAdmin(revisions, NewRuleSource())
+	if restored, err := admin.Restore(); err != nil || restored {
+		t.Errorf("Restore = %v, %v on an empty store, want nothing restored", restored, err)
+	}
+
+	for range 2 {
+		if _, err := admin.Create("alice", "", cls.RuleSetConfig{}, true); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, err := admin.Activate("r1", "alice"); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	revisions.Close()
+
+	// A restart puts the revision activated last back into service
+	rules := NewRuleSource()
+	admin = NewRuleAdmin(openTestStore(t, path), rules)
+
+	restored, err := admin.Restore()
+	if err != nil || !restored {
+		t.Fatalf("Restore = %v, %v, want the active revision restored", restored, err)
+	}
+	if got := rules.Current().Version; got != "r1" {
+		t.Errorf("version = %q after restoring, want r1", got)
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+)
+
+// DefaultRuleReloadInterval is how often a watched rule set config is checked for changes.
+const DefaultRuleReloadInterval = 10 * time.Second
+
+// ErrRuleSetPinned is returned when reloading the rule set config while a rule
+// set put into service with Set, e.g. an admin revision, takes precedence over it.
+var ErrRuleSetPinned = errors.New("a rule set revision is in service, config changes are not applied")
+
+// RuleSource holds the active classification rule set. A new rule set is
+// swapped in atomically, so a request classified with a snapshot taken by
+// Current is not affected by reloads happening meanwhile.
+//
+// A rule set put into service with Set takes precedence over the config file,
+// whose later changes are no longer applied.
+type RuleSource struct {
+	current atomic.Pointer[cls.RuleSet]
+	path    string
+
+	// mu serializes reloads and Set, and guards the fields below.
+	mu sync.Mutex
+	// hash is the hash of the last config read.
+	hash [sha256.Size]byte
+	// pinned is set once a rule set was put into service with Set.
+	pinned bool
+}
+
+// NewRuleSource returns a source serving the built-in rule set.
+func NewRuleSource() *RuleSource {
+	s := &RuleSource{}
+	s.current.Store(cls.BuiltinRuleSet())
+	return s
+}
+
+// LoadRuleSource returns a source serving the rule set config at path, which
+// can be reloaded later. It fails if the config is invalid.
+func LoadRuleSource(path string) (*RuleSource, error) {
+	s := &RuleSource{path: path}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Current returns the active rule set.
+func (s *RuleSource) Current() *cls.RuleSet {
+	return s.current.Load()
+}
+
+// Set activates the given rule set, e.g. a stored revision. It takes precedence
+// over the config file from then on.
+func (s *RuleSource) Set(rs *cls.RuleSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pinned = true
+	s.current.Store(rs)
+}
+
+// Reload reads, validates and activates the rule set config. The active rule
+// set is kept if the config is invalid, or with ErrRuleSetPinned once a rule
+// set was put into service with Set.
+func (s *RuleSource) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	return s.load(data)
+}
+
+// load activates the rule set config with the given content. The caller holds mu.
+func (s *RuleSource) load(data []byte) error {
+	s.hash = sha256.Sum256(data)
+
+	if s.pinned {
+		return fmt.Errorf("%s: %w", s.path, ErrRuleSetPinned)
+	}
+
+	rs, err := cls.ParseRuleSet(data, strings.TrimPrefix(filepath.Ext(s.path), "."))
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	s.current.Store(rs)
+	return nil
+}
+
+// Watch reloads the rule set config whenever its content changes, checking it
+// at the given interval, and whenever a value is received on reload, e.g. on
+// SIGHUP. Invalid configs are logged and the active rule set is kept. Watch
+// returns when the context is done, and does nothing for built-in rules.
+func (s *RuleSource) Watch(ctx context.Context, interval time.Duration, reload <-chan os.Signal) {
+	if s.path == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultRuleReloadInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reloadIfChanged()
+		case <-reload:
+			s.logReload(s.Reload())
+		}
+	}
+}
+
+// reloadIfChanged reloads the config if its content differs from the last one
+// read, so that an invalid or ignored config is only reported once.
+func (s *RuleSource) reloadIfChanged() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		logger.Log.Warn("Failed to read rule set config", zap.String("path", s.path), zap.Error(err))
+		return
+	}
+
+	if sha256.Sum256(data) == s.hash {
+		return
+	}
+
+	s.logReload(s.load(data))
+}
+
+func (s *RuleSource) logReload(err error) {
+	if errors.Is(err, ErrRuleSetPinned) {
+		logger.Log.Warn("Rule set config changed while a rule set revision is in service, not applied",
+			zap.String("path", s.path),
+			zap.String("version", s.Current().Version),
+		)
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Rule set config rejected, keeping the active rules",
+			zap.String("version", s.Current().Version),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logger.Log.Info("Classification rules reloaded", zap.String("version", s.Current().Version))
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cls "github.com/ihgazi/EventWeatherGuard/service/classification"
+)
+
+func writeRules(t *testing.T, path, version string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte("version: "+version+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRuleSourceReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, "file-1")
+
+	s, err := LoadRuleSource(path)
+	if err != nil {
+		t.Fatalf("LoadRuleSource: %v", err)
+	}
+
+	writeRules(t, path, "file-2")
+	s.reloadIfChanged()
+	if got := s.Current().Version; got != "file-2" {
+		t.Errorf("version = %q after a config change, want file-2", got)
+	}
+}
+
+func TestRuleSourceSetTakesPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, "file-1")
+
+	s, err := LoadRuleSource(path)
+	if err != nil {
+		t.Fatalf("LoadRuleSource: %v", err)
+	}
+
+	revision, err := cls.RuleSetConfig{Version: "revision-3"}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set(revision)
+
+	// Neither a config change nor SIGHUP replaces the revision in service
+	writeRules(t, path, "file-2")
+	s.reloadIfChanged()
+	if err := s.Reload(); !errors.Is(err, ErrRuleSetPinned) {
+		t.Errorf("Reload: err = %v, want ErrRuleSetPinned", err)
+	}
+	if got := s.Current().Version; got != "revision-3" {
+		t.Errorf("version = %q, want the revision set", got)
+	}
+}
@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
-	"github.com/relvacode/iso8601"
 	"go.uber.org/zap"
 
 	"github.com/ihgazi/EventWeatherGuard/client"
@@ -13,66 +14,200 @@ import (
 )
 
 // WeatherService provides methods to fetch and process weather forecasts
-// using an external API client.
+// from one or more pluggable weather providers.
 type WeatherService struct {
-	client *client.OpenMeteoClient
+	providers  []client.WeatherProvider
+	strategy   MergeStrategy
+	airQuality client.AirQualityProvider
+	marine     client.MarineProvider
 }
 
-func NewWeatherService(client *client.OpenMeteoClient) *WeatherService {
+func NewWeatherService(provider client.WeatherProvider) *WeatherService {
 	return &WeatherService{
-		client: client,
+		providers: []client.WeatherProvider{provider},
+		strategy:  DefaultMergeStrategy,
 	}
 }
 
+// NewConsensusWeatherService creates a WeatherService that queries all the providers
+// concurrently and merges their forecasts hour by hour using the given strategy.
+func NewConsensusWeatherService(strategy MergeStrategy, providers ...client.WeatherProvider) *WeatherService {
+	return &WeatherService{
+		providers: providers,
+		strategy:  strategy,
+	}
+}
+
+// WithAirQuality adds the air quality forecast of the given provider to the
+// hourly forecasts returned by the service.
+func (s *WeatherService) WithAirQuality(provider client.AirQualityProvider) *WeatherService {
+	s.airQuality = provider
+	return s
+}
+
+// WithMarine adds the sea state forecast of the given provider to the hourly
+// forecasts of on-water events.
+func (s *WeatherService) WithMarine(provider client.MarineProvider) *WeatherService {
+	s.marine = provider
+	return s
+}
+
 // GetEventForecast retrieves and processes hourly weather forecasts for a given event location and time window.
 // It filters the forecast data to only include hours within the specified start and end times.
+// The marine forecast is only included for on-water venues.
 func (s *WeatherService) GetEventForecast(
 	ctx context.Context,
 	lat, long float64,
 	start, end time.Time,
+	venue model.VenueType,
 ) ([]model.HourlyForecast, error) {
 
-	raw, err := s.client.FetchWeatherData(ctx, lat, long)
+	// Air quality and sea state are fetched alongside the weather, and are optional
+	var airQuality []model.HourlyAirQuality
+	var marine []model.HourlyMarine
+	var wg sync.WaitGroup
+	if s.airQuality != nil {
+		wg.Go(func() {
+			var err error
+			airQuality, err = s.airQuality.FetchHourlyAirQuality(ctx, lat, long)
+			if err != nil {
+				logger.Log.Warn("Air quality provider failed",
+					zap.String("provider", s.airQuality.Name()),
+					zap.Error(err),
+				)
+			}
+		})
+	}
+	if s.marine != nil && venue == model.VenueWater {
+		wg.Go(func() {
+			var err error
+			marine, err = s.marine.FetchHourlyMarine(ctx, lat, long)
+			if err != nil {
+				logger.Log.Warn("Marine provider failed",
+					zap.String("provider", s.marine.Name()),
+					zap.Error(err),
+				)
+			}
+		})
+	}
+
+	hours, err := s.fetchForecast(ctx, lat, long)
+	wg.Wait()
 	if err != nil {
 		return nil, err
 	}
 
 	var result []model.HourlyForecast
 
-	for i, t := range raw.Hourly.Time {
-		parsed, err := iso8601.ParseString(t)
+	for _, h := range hours {
+		// Time interval falls outside window
+		if h.Time.Before(start) || h.Time.After(end) || h.Time.Equal(end) {
+			continue
+		}
+
+		result = append(result, h)
+	}
+
+	// The filtered hours are copies, so they can be completed without
+	// affecting forecasts shared with a provider cache
+	attach(result, airQuality, func(aq model.HourlyAirQuality) time.Time { return aq.Time },
+		func(h *model.HourlyForecast, aq model.HourlyAirQuality) { h.AirQuality = &aq.AirQuality })
+	attach(result, marine, func(m model.HourlyMarine) time.Time { return m.Time },
+		func(h *model.HourlyForecast, m model.HourlyMarine) { h.Marine = &m.Marine })
+
+	return result, nil
+}
+
+// fetchForecast queries every provider concurrently and merges their forecasts.
+// Providers that fail are left out of the consensus; an error is only returned
+// when none of them succeeded.
+func (s *WeatherService) fetchForecast(ctx context.Context, lat, long float64) ([]model.HourlyForecast, error) {
+	if len(s.providers) == 1 {
+		return s.providers[0].FetchHourlyForecast(ctx, lat, long)
+	}
+
+	series := make([][]model.HourlyForecast, len(s.providers))
+	errs := make([]error, len(s.providers))
+
+	var wg sync.WaitGroup
+	for i, p := range s.providers {
+		wg.Go(func() {
+			series[i], errs[i] = p.FetchHourlyForecast(ctx, lat, long)
+		})
+	}
+	wg.Wait()
+
+	var succeeded [][]model.HourlyForecast
+	for i, err := range errs {
 		if err != nil {
-			logger.Log.Error("Failed to parse time: %v", zap.Error(err))
+			logger.Log.Warn("Weather provider failed",
+				zap.String("provider", s.providers[i].Name()),
+				zap.Error(err),
+			)
 			continue
 		}
+		succeeded = append(succeeded, series[i])
+	}
 
-		// Time interval falls outside window
-		if parsed.Before(start) || parsed.After(end) || parsed.Equal(end) {
+	if len(succeeded) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return mergeForecasts(succeeded, s.strategy), nil
+}
+
+// UpstreamStatus reports the circuit breaker state of the upstream providers,
+// once per breaker.
+func (s *WeatherService) UpstreamStatus() []model.BreakerStatus {
+	statuses := []model.BreakerStatus{}
+
+	upstreams := make([]any, 0, len(s.providers)+1)
+	for _, p := range s.providers {
+		upstreams = append(upstreams, p)
+	}
+	if s.airQuality != nil {
+		upstreams = append(upstreams, s.airQuality)
+	}
+	if s.marine != nil {
+		upstreams = append(upstreams, s.marine)
+	}
+
+	// Providers may share a client, e.g. Open-Meteo as the NWS fallback
+	seen := make(map[*client.CircuitBreaker]bool)
+
+	for _, p := range upstreams {
+		r, ok := p.(client.BreakerReporter)
+		if !ok {
 			continue
 		}
 
-		result = append(result, model.HourlyForecast{
-			Time:          parsed,
-			RainProb:      raw.Hourly.PrecipitationProbability[i],
-			Precipitation: raw.Hourly.Rain[i],
-			WindKmh:       raw.Hourly.WindSpeed10m[i],
-			Weather:       weatherCodeToLabel(raw.Hourly.WeatherCode[i]),
-		})
+		for _, b := range r.Breakers() {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+			statuses = append(statuses, b.Status())
+		}
 	}
 
-	return result, nil
+	return statuses
 }
 
-// Map WMO weather codes to human-readable labels
-func weatherCodeToLabel(code int) string {
-	switch {
-	case code >= 95:
-		return "Thunderstorm"
-	case code >= 80:
-		return "Heavy Rain"
-	case code >= 60:
-		return "Rain Showers"
-	default:
-		return "Clear"
+// attach sets the values of a supplementary hourly series on the hours it covers.
+func attach[T any](
+	hours []model.HourlyForecast,
+	series []T,
+	timeOf func(T) time.Time,
+	set func(*model.HourlyForecast, T),
+) {
+	byHour := make(map[time.Time]T, len(series))
+	for _, v := range series {
+		byHour[timeOf(v)] = v
+	}
+
+	for i := range hours {
+		if v, ok := byHour[hours[i].Time]; ok {
+			set(&hours[i], v)
+		}
 	}
 }
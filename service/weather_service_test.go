@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ihgazi/EventWeatherGuard/client"
+	"github.com/ihgazi/EventWeatherGuard/logger"
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeProvider serves a fixed forecast.
+type fakeProvider struct {
+	name  string
+	hours []model.HourlyForecast
+	err   error
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) FetchHourlyForecast(context.Context, float64, float64) ([]model.HourlyForecast, error) {
+	return p.hours, p.err
+}
+
+// hourlySeries returns n consecutive hours from start, each built by fill.
+func hourlySeries(start time.Time, n int, fill func(i int, h *model.HourlyForecast)) []model.HourlyForecast {
+	hours := make([]model.HourlyForecast, n)
+	for i := range hours {
+		hours[i].Time = start.Add(time.Duration(i) * time.Hour)
+		if fill != nil {
+			fill(i, &hours[i])
+		}
+	}
+	return hours
+}
+
+func TestGetEventForecastWindow(t *testing.T) {
+	day := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewWeatherService(&fakeProvider{name: "fake", hours: hourlySeries(day, 24, nil)})
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []int
+	}{
+		{"whole hours", day.Add(10 * time.Hour), day.Add(13 * time.Hour), []int{10, 11, 12}},
+		{"end excluded", day.Add(10 * time.Hour), day.Add(11 * time.Hour), []int{10}},
+		{"start within hour", day.Add(10*time.Hour + 30*time.Minute), day.Add(13 * time.Hour), []int{11, 12}},
+		{"end within hour", day.Add(10 * time.Hour), day.Add(12*time.Hour + 30*time.Minute), []int{10, 11, 12}},
+		{"before forecast", day.Add(-5 * time.Hour), day.Add(2 * time.Hour), []int{0, 1}},
+		{"after forecast", day.Add(30 * time.Hour), day.Add(32 * time.Hour), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, err := svc.GetEventForecast(context.Background(), 52.52, 13.41, tt.start, tt.end, model.VenueLand)
+			if err != nil {
+				t.Fatalf("GetEventForecast: %v", err)
+			}
+
+			var got []int
+			for _, h := range hours {
+				got = append(got, h.Time.Hour())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("hours = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEventForecastProviderError(t *testing.T) {
+	want := context.DeadlineExceeded
+	svc := NewWeatherService(&fakeProvider{name: "fake", err: want})
+
+	start := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := svc.GetEventForecast(context.Background(), 0, 0, start, start.Add(time.Hour), model.VenueLand); !errors.Is(err, want) {
+		t.Errorf("err = %v, want %v", err, want)
+	}
+}
+
+// breakerProvider is a fake provider guarded by circuit breakers.
+type breakerProvider struct {
+	fakeProvider
+	breakers []*client.CircuitBreaker
+}
+
+func (p *breakerProvider) Breakers() []*client.CircuitBreaker { return p.breakers }
+
+func TestUpstreamStatusSharedBreaker(t *testing.T) {
+	openMeteo := client.NewCircuitBreaker("open-meteo", client.BreakerOptions{})
+	nws := client.NewCircuitBreaker("nws", client.BreakerOptions{})
+
+	svc := NewConsensusWeatherService(DefaultMergeStrategy,
+		&breakerProvider{fakeProvider{name: "open-meteo"}, []*client.CircuitBreaker{openMeteo}},
+		&breakerProvider{fakeProvider{name: "nws|open-meteo"}, []*client.CircuitBreaker{nws, openMeteo}},
+	)
+
+	var got []string
+	for _, s := range svc.UpstreamStatus() {
+		got = append(got, s.Provider)
+	}
+	if want := []string{"open-meteo", "nws"}; !slices.Equal(got, want) {
+		t.Errorf("providers = %v, want %v", got, want)
+	}
+}
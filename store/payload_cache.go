@@ -0,0 +1,222 @@
+// Package store provides embedded persistent storage for the service, backed by bbolt.
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var payloadBucket = []byte("payloads")
+
+// Defaults for PayloadCacheOptions
+const (
+	DefaultPayloadExpiry   = 6 * time.Hour
+	DefaultPayloadMaxBytes = 64 << 20
+)
+
+// PayloadCacheOptions configures a PayloadCache. Zero values fall back to the defaults.
+type PayloadCacheOptions struct {
+	// Expiry is the age after which payloads are discarded.
+	Expiry time.Duration
+	// MaxBytes bounds the total size of the stored payloads. The oldest payloads
+	// are evicted first once it is exceeded.
+	MaxBytes int64
+}
+
+// PayloadCache is an on-disk cache of raw upstream payloads that survives restarts.
+//
+// Each value is stored as an 8-byte big-endian timestamp of when it was stored,
+// followed by the payload itself.
+type PayloadCache struct {
+	db       *bolt.DB
+	expiry   time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	// size is the total size of the stored keys and values, and lastSweep the
+	// time expired payloads were last removed. Both are only accessed within
+	// update transactions, which bbolt serializes.
+	size      int64
+	lastSweep time.Time
+}
+
+// OpenPayloadCache opens (or creates) the cache database at path.
+func OpenPayloadCache(path string, opts PayloadCacheOptions) (*PayloadCache, error) {
+	if opts.Expiry <= 0 {
+		opts.Expiry = DefaultPayloadExpiry
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultPayloadMaxBytes
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &PayloadCache{
+		db:       db,
+		expiry:   opts.Expiry,
+		maxBytes: opts.MaxBytes,
+		now:      time.Now,
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(payloadBucket)
+		if err != nil {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			c.size += int64(len(k) + len(v))
+			return nil
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	c.lastSweep = c.now()
+	return c, nil
+}
+
+// Close releases the underlying database.
+func (c *PayloadCache) Close() error {
+	return c.db.Close()
+}
+
+// Get returns the payload stored under key and when it was stored.
+// Expired payloads are reported as missing.
+func (c *PayloadCache) Get(key string) ([]byte, time.Time, bool, error) {
+	var payload []byte
+	var storedAt time.Time
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(payloadBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+
+		var err error
+		if storedAt, err = decodeStoredAt(v); err != nil {
+			return err
+		}
+
+		// Values are only valid for the lifetime of the transaction
+		payload = append([]byte(nil), v[8:]...)
+		return nil
+	})
+	if err != nil || payload == nil {
+		return nil, time.Time{}, false, err
+	}
+
+	if c.now().Sub(storedAt) >= c.expiry {
+		return nil, time.Time{}, false, nil
+	}
+
+	return payload, storedAt, true, nil
+}
+
+// Put stores the payload under key. Once the cache exceeds its size limit, or
+// an expiry period after the last sweep, it evicts the expired payloads and,
+// if still too large, the oldest ones.
+func (c *PayloadCache) Put(key string, payload []byte) error {
+	now := c.now()
+
+	value := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint64(value, uint64(now.UnixNano()))
+	copy(value[8:], payload)
+
+	// A failed transaction is rolled back, and so is the accounting
+	prevSize, prevSweep := c.size, c.lastSweep
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(payloadBucket)
+
+		size := c.size + int64(len(key)+len(value))
+		if old := b.Get([]byte(key)); old != nil {
+			size -= int64(len(key) + len(old))
+		}
+		if err := b.Put([]byte(key), value); err != nil {
+			return err
+		}
+		c.size = size
+
+		if c.size <= c.maxBytes && now.Sub(c.lastSweep) < c.expiry {
+			return nil
+		}
+		return c.evict(b, now)
+	})
+	if err != nil {
+		c.size, c.lastSweep = prevSize, prevSweep
+	}
+
+	return err
+}
+
+// evict removes expired payloads and then the oldest payloads until the cache
+// is a tenth below its size limit, so that the following writes do not have
+// to evict again right away.
+func (c *PayloadCache) evict(b *bolt.Bucket, now time.Time) error {
+	type item struct {
+		key      []byte
+		storedAt time.Time
+		size     int64
+	}
+
+	var items []item
+
+	err := b.ForEach(func(k, v []byte) error {
+		storedAt, err := decodeStoredAt(v)
+		if err != nil {
+			return err
+		}
+
+		// Keys are only valid for the lifetime of the transaction
+		items = append(items, item{
+			key:      append([]byte(nil), k...),
+			storedAt: storedAt,
+			size:     int64(len(k) + len(v)),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].storedAt.Before(items[j].storedAt)
+	})
+
+	target := c.maxBytes
+	if c.size > c.maxBytes {
+		target -= c.maxBytes / 10
+	}
+
+	for _, it := range items {
+		if c.size <= target && now.Sub(it.storedAt) < c.expiry {
+			break
+		}
+
+		if err := b.Delete(it.key); err != nil {
+			return err
+		}
+		c.size -= it.size
+	}
+
+	c.lastSweep = now
+	return nil
+}
+
+// decodeStoredAt returns when a value was stored.
+func decodeStoredAt(v []byte) (time.Time, error) {
+	if len(v) < 8 {
+		return time.Time{}, errors.New("corrupt payload cache entry")
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(v[:8]))), nil
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestCache opens a cache in a temporary directory, with a clock
+// returning the time pointed to by now.
+func openTestCache(t *testing.T, path string, opts PayloadCacheOptions, now *time.Time) *PayloadCache {
+	t.Helper()
+
+	c, err := OpenPayloadCache(path, opts)
+	if err != nil {
+		t.Fatalf("OpenPayloadCache: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	c.now = func() time.Time { return *now }
+	c.lastSweep = *now
+	return c
+}
+
+func TestPayloadCacheExpiry(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	c := openTestCache(t, filepath.Join(t.TempDir(), "payloads.db"), PayloadCacheOptions{Expiry: time.Hour}, &now)
+
+	if err := c.Put("a", []byte("payload")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	now = now.Add(59 * time.Minute)
+	payload, storedAt, ok, err := c.Get("a")
+	if err != nil || !ok || !bytes.Equal(payload, []byte("payload")) {
+		t.Fatalf("Get = %q, %v, %v, want the payload", payload, ok, err)
+	}
+	if want := now.Add(-59 * time.Minute); !storedAt.Equal(want) {
+		t.Errorf("storedAt = %v, want %v", storedAt, want)
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, ok, err := c.Get("a"); ok || err != nil {
+		t.Errorf("Get = %v, %v, want an expired payload reported missing", ok, err)
+	}
+
+	// The next write an expiry period after the last sweep removes it
+	if err := c.Put("b", []byte("payload")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if want := int64(len("b") + 8 + len("payload")); c.size != want {
+		t.Errorf("size = %d after the sweep, want %d", c.size, want)
+	}
+}
+
+func TestPayloadCacheSizeEviction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payloads.db")
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	// Entries take 100 bytes: a 2-byte key, the timestamp and a 90-byte payload
+	c := openTestCache(t, path, PayloadCacheOptions{MaxBytes: 350}, &now)
+
+	payload := bytes.Repeat([]byte("x"), 90)
+	for i := range 3 {
+		now = now.Add(time.Minute)
+		if err := c.Put(fmt.Sprintf("k%d", i), payload); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	// Replacing an entry does not grow the cache
+	if err := c.Put("k0", payload); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if c.size != 300 {
+		t.Fatalf("size = %d, want 300", c.size)
+	}
+
+	// Crossing the limit evicts the oldest entries down to 315 bytes, the
+	// replaced k0 now being newer than k1
+	now = now.Add(time.Minute)
+	if err := c.Put("k3", payload); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if c.size != 300 {
+		t.Errorf("size = %d after eviction, want 300", c.size)
+	}
+
+	for key, want := range map[string]bool{"k0": true, "k1": false, "k2": true, "k3": true} {
+		if _, _, ok, err := c.Get(key); err != nil || ok != want {
+			t.Errorf("Get(%s) = %v, %v, want %v", key, ok, err, want)
+		}
+	}
+
+	// The size is recomputed when the cache is reopened
+	c.Close()
+	reopened := openTestCache(t, path, PayloadCacheOptions{MaxBytes: 350}, &now)
+	if reopened.size != 300 {
+		t.Errorf("size = %d after reopening, want 300", reopened.size)
+	}
+}
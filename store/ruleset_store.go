@@ -0,0 +1,229 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/ihgazi/EventWeatherGuard/model"
+)
+
+var (
+	revisionBucket   = []byte("ruleset_revisions")
+	activationBucket = []byte("ruleset_activations")
+)
+
+// ErrRevisionNotFound is returned for unknown rule set versions.
+var ErrRevisionNotFound = errors.New("rule set revision not found")
+
+// RuleSetStore keeps the revisions of the rule set config, and a log of which
+// revision was put into service, by whom and when.
+//
+// Revisions are keyed by an 8-byte big-endian sequence number, which their
+// version ("r1", "r2", ...) is derived from, and activations likewise.
+type RuleSetStore struct {
+	db *bolt.DB
+}
+
+// OpenRuleSetStore opens (or creates) the rule set database at path.
+func OpenRuleSetStore(path string) (*RuleSetStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{revisionBucket, activationBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &RuleSetStore{db: db}, nil
+}
+
+// Close releases the underlying database.
+func (s *RuleSetStore) Close() error {
+	return s.db.Close()
+}
+
+// Create stores a new revision. The config is built by build from the version
+// assigned to the revision, and nothing is stored if it fails.
+func (s *RuleSetStore) Create(
+	author, comment string,
+	build func(version string) (json.RawMessage, error),
+) (model.RuleSetRevision, error) {
+	var rev model.RuleSetRevision
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(revisionBucket)
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		rev = model.RuleSetRevision{
+			Version:   "r" + strconv.FormatUint(seq, 10),
+			Author:    author,
+			CreatedAt: time.Now().UTC(),
+			Comment:   comment,
+		}
+		if rev.Config, err = build(rev.Version); err != nil {
+			return err
+		}
+
+		v, err := json.Marshal(rev)
+		if err != nil {
+			return err
+		}
+		return b.Put(sequenceKey(seq), v)
+	})
+	if err != nil {
+		return model.RuleSetRevision{}, err
+	}
+
+	return rev, nil
+}
+
+// Get returns the revision with the given version.
+func (s *RuleSetStore) Get(version string) (model.RuleSetRevision, error) {
+	var rev model.RuleSetRevision
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		rev, err = getRevision(tx, version)
+		return err
+	})
+
+	return rev, err
+}
+
+// List returns every revision, oldest first, without their config.
+func (s *RuleSetStore) List() ([]model.RuleSetRevision, error) {
+	revisions := []model.RuleSetRevision{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(revisionBucket).ForEach(func(_, v []byte) error {
+			var rev model.RuleSetRevision
+			if err := json.Unmarshal(v, &rev); err != nil {
+				return err
+			}
+
+			rev.Config = nil
+			revisions = append(revisions, rev)
+			return nil
+		})
+	})
+
+	return revisions, err
+}
+
+// Activate records the revision with the given version being put into service.
+func (s *RuleSetStore) Activate(version, author string) (model.RuleSetActivation, error) {
+	activation := model.RuleSetActivation{
+		Version:     version,
+		Author:      author,
+		ActivatedAt: time.Now().UTC(),
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if _, err := getRevision(tx, version); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(activationBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		v, err := json.Marshal(activation)
+		if err != nil {
+			return err
+		}
+		return b.Put(sequenceKey(seq), v)
+	})
+	if err != nil {
+		return model.RuleSetActivation{}, err
+	}
+
+	return activation, nil
+}
+
+// Activations returns the activation log, oldest first.
+func (s *RuleSetStore) Activations() ([]model.RuleSetActivation, error) {
+	activations := []model.RuleSetActivation{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(activationBucket).ForEach(func(_, v []byte) error {
+			var a model.RuleSetActivation
+			if err := json.Unmarshal(v, &a); err != nil {
+				return err
+			}
+
+			activations = append(activations, a)
+			return nil
+		})
+	})
+
+	return activations, err
+}
+
+// Active returns the revision activated last, if any.
+func (s *RuleSetStore) Active() (model.RuleSetRevision, bool, error) {
+	var rev model.RuleSetRevision
+	var found bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		_, v := tx.Bucket(activationBucket).Cursor().Last()
+		if v == nil {
+			return nil
+		}
+
+		var a model.RuleSetActivation
+		if err := json.Unmarshal(v, &a); err != nil {
+			return err
+		}
+
+		var err error
+		rev, err = getRevision(tx, a.Version)
+		found = err == nil
+		return err
+	})
+
+	return rev, found, err
+}
+
+func getRevision(tx *bolt.Tx, version string) (model.RuleSetRevision, error) {
+	seq, err := strconv.ParseUint(strings.TrimPrefix(version, "r"), 10, 64)
+	if err != nil || !strings.HasPrefix(version, "r") {
+		return model.RuleSetRevision{}, ErrRevisionNotFound
+	}
+
+	v := tx.Bucket(revisionBucket).Get(sequenceKey(seq))
+	if v == nil {
+		return model.RuleSetRevision{}, ErrRevisionNotFound
+	}
+
+	var rev model.RuleSetRevision
+	err = json.Unmarshal(v, &rev)
+	return rev, err
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}